@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"mxshs/pyinterpreter/token"
 	"strings"
 )
@@ -9,6 +10,8 @@ import (
 type Node interface {
     TokenLiteral() string
     String() string
+    // Pos returns the position where the node starts in the source.
+    Pos() token.Position
 }
 
 type Statement interface {
@@ -33,6 +36,14 @@ func (p *Program) TokenLiteral() string {
     }
 }
 
+func (p *Program) Pos() token.Position {
+    if len(p.Statements) > 0 {
+        return p.Statements[0].Pos()
+    }
+
+    return token.Position{}
+}
+
 func (p *Program) String() string {
     var out bytes.Buffer
 
@@ -43,9 +54,12 @@ func (p *Program) String() string {
     return out.String()
 }
 
+// AssignStatement binds Value to Name, or stores it into Target when the
+// left hand side is not a plain name (e.g. a subscript).
 type AssignStatement struct {
     Token token.Token
     Name *Name
+    Target Expression
     Value Expression
 }
 
@@ -56,10 +70,22 @@ func (as *AssignStatement) TokenLiteral() string {
     return as.Token.Literal
 }
 
+func (as *AssignStatement) Pos() token.Position {
+    if as.Name == nil {
+        return as.Target.Pos()
+    }
+
+    return as.Name.Pos()
+}
+
 func (as *AssignStatement) String() string {
     var out bytes.Buffer
 
-    out.WriteString(as.Name.String() + " ")
+    if as.Name == nil {
+        out.WriteString(as.Target.String() + " ")
+    } else {
+        out.WriteString(as.Name.String() + " ")
+    }
     out.WriteString(as.TokenLiteral() + " ")
     
     if as.Value != nil {
@@ -69,6 +95,38 @@ func (as *AssignStatement) String() string {
     return out.String()
 }
 
+// AugAssignStatement applies Operator to Target and Value and stores the
+// result back into Target, e.g. "x += 1".
+type AugAssignStatement struct {
+    Token token.Token
+    Target Expression
+    Operator string
+    Value Expression
+}
+
+func (as *AugAssignStatement) statementNode() {}
+
+func (as *AugAssignStatement) TokenLiteral() string {
+    return as.Token.Literal
+}
+
+func (as *AugAssignStatement) Pos() token.Position {
+    return as.Target.Pos()
+}
+
+func (as *AugAssignStatement) String() string {
+    var out bytes.Buffer
+
+    out.WriteString(as.Target.String() + " ")
+    out.WriteString(as.TokenLiteral() + " ")
+
+    if as.Value != nil {
+        out.WriteString(as.Value.String())
+    }
+
+    return out.String()
+}
+
 type Name struct {
     Token token.Token
     Value string
@@ -81,6 +139,10 @@ func (n *Name) TokenLiteral() string {
     return n.Token.Literal
 }
 
+func (n *Name) Pos() token.Position {
+    return n.Token.Pos
+}
+
 func (n *Name) String() string {
     return n.Value
 } 
@@ -96,6 +158,10 @@ func (rs *ReturnStatement) TokenLiteral() string {
     return rs.Token.Literal
 }
 
+func (rs *ReturnStatement) Pos() token.Position {
+    return rs.Token.Pos
+}
+
 func (rs *ReturnStatement) String() string {
     var out bytes.Buffer
 
@@ -119,6 +185,10 @@ func (es *ExpressionStatement) TokenLiteral() string {
     return es.Token.Literal 
 }
 
+func (es *ExpressionStatement) Pos() token.Position {
+    return es.Token.Pos
+}
+
 func (es *ExpressionStatement) String() string {
 
     if es.Expression != nil {
@@ -128,9 +198,12 @@ func (es *ExpressionStatement) String() string {
     return ""
 }
 
+// IntegerLiteral is an int literal, Big holds its value instead of Value
+// if it doesn't fit in an int64.
 type IntegerLiteral struct {
     Token token.Token
     Value int64
+    Big *big.Int
 }
 
 func (il *IntegerLiteral) expressionNode() {}
@@ -138,6 +211,11 @@ func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) TokenLiteral() string {
     return il.Token.Literal
 }
+
+func (il *IntegerLiteral) Pos() token.Position {
+    return il.Token.Pos
+}
+
 func (il *IntegerLiteral) String() string {
     return il.Token.Literal
 }
@@ -152,10 +230,35 @@ func (fl *FloatLiteral) expressionNode() {}
 func (fl *FloatLiteral) TokenLiteral() string {
     return fl.Token.Literal
 }
+
+func (fl *FloatLiteral) Pos() token.Position {
+    return fl.Token.Pos
+}
+
 func (fl *FloatLiteral) String() string {
     return fl.Token.Literal
 }
 
+// ImaginaryLiteral is a literal like 2j, Value is its imaginary part.
+type ImaginaryLiteral struct {
+    Token token.Token
+    Value float64
+}
+
+func (il *ImaginaryLiteral) expressionNode() {}
+
+func (il *ImaginaryLiteral) TokenLiteral() string {
+    return il.Token.Literal
+}
+
+func (il *ImaginaryLiteral) Pos() token.Position {
+    return il.Token.Pos
+}
+
+func (il *ImaginaryLiteral) String() string {
+    return il.Token.Literal
+}
+
 type Boolean struct {
     Token token.Token
     Value bool
@@ -167,6 +270,10 @@ func (b *Boolean) TokenLiteral() string {
     return b.Token.Literal
 }
 
+func (b *Boolean) Pos() token.Position {
+    return b.Token.Pos
+}
+
 func (b *Boolean) String() string {
     return b.Token.Literal
 }
@@ -183,14 +290,21 @@ func (s *StringLiteral) TokenLiteral() string {
     return s.Token.Literal
 }
 
+func (s *StringLiteral) Pos() token.Position {
+    return s.Token.Pos
+}
+
 func (s *StringLiteral) String() string {
     return s.Token.Literal
 }
 
+// IfExpression is an if statement, its elif clauses are tried in order
+// when Condition is false, before falling back to Alternative.
 type IfExpression struct {
     Token token.Token
     Condition Expression
     Consequence *BlockStatement
+    Elifs []*ElifClause
     Alternative *BlockStatement
 }
 
@@ -200,6 +314,10 @@ func (ie *IfExpression) TokenLiteral() string {
     return ie.Token.Literal
 }
 
+func (ie *IfExpression) Pos() token.Position {
+    return ie.Token.Pos
+}
+
 func (ie *IfExpression) String() string {
     var out bytes.Buffer
 
@@ -207,6 +325,10 @@ func (ie *IfExpression) String() string {
     out.WriteString(ie.Token.Literal + " ")
     out.WriteString(ie.Condition.String() + " ")
     out.WriteString(ie.Consequence.String())
+
+    for _, elif := range ie.Elifs {
+        out.WriteString(" " + elif.String())
+    }
     
     if ie.Alternative != nil {
         out.WriteString(" else ")
@@ -218,6 +340,276 @@ func (ie *IfExpression) String() string {
     return out.String()
 }
 
+// ElifClause is an elif clause of an if statement.
+type ElifClause struct {
+    Token token.Token
+    Condition Expression
+    Consequence *BlockStatement
+}
+
+func (ec *ElifClause) TokenLiteral() string {
+    return ec.Token.Literal
+}
+
+func (ec *ElifClause) Pos() token.Position {
+    return ec.Token.Pos
+}
+
+func (ec *ElifClause) String() string {
+    return ec.Token.Literal + " " + ec.Condition.String() + " " + ec.Consequence.String()
+}
+
+// ConditionalExpression is an expression like "a if cond else b".
+type ConditionalExpression struct {
+    Token token.Token
+    Condition Expression
+    Consequence Expression
+    Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode() {}
+
+func (ce *ConditionalExpression) TokenLiteral() string {
+    return ce.Token.Literal
+}
+
+func (ce *ConditionalExpression) Pos() token.Position {
+    return ce.Consequence.Pos()
+}
+
+func (ce *ConditionalExpression) String() string {
+    return "(" + ce.Consequence.String() + " if " + ce.Condition.String() +
+        " else " + ce.Alternative.String() + ")"
+}
+
+type WhileStatement struct {
+    Token token.Token
+    Condition Expression
+    Body *BlockStatement
+    Alternative *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+func (ws *WhileStatement) TokenLiteral() string {
+    return ws.Token.Literal
+}
+
+func (ws *WhileStatement) Pos() token.Position {
+    return ws.Token.Pos
+}
+
+func (ws *WhileStatement) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(")
+    out.WriteString(ws.Token.Literal + " ")
+    out.WriteString(ws.Condition.String() + " ")
+    out.WriteString(ws.Body.String())
+
+    if ws.Alternative != nil {
+        out.WriteString(" else ")
+        out.WriteString(ws.Alternative.String())
+    }
+
+    out.WriteString(")")
+
+    return out.String()
+}
+
+type ForStatement struct {
+    Token token.Token
+    Target Expression
+    Iterable Expression
+    Body *BlockStatement
+    Alternative *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+func (fs *ForStatement) TokenLiteral() string {
+    return fs.Token.Literal
+}
+
+func (fs *ForStatement) Pos() token.Position {
+    return fs.Token.Pos
+}
+
+func (fs *ForStatement) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(")
+    out.WriteString(fs.Token.Literal + " ")
+    out.WriteString(fs.Target.String() + " in ")
+    out.WriteString(fs.Iterable.String() + " ")
+    out.WriteString(fs.Body.String())
+
+    if fs.Alternative != nil {
+        out.WriteString(" else ")
+        out.WriteString(fs.Alternative.String())
+    }
+
+    out.WriteString(")")
+
+    return out.String()
+}
+
+type BreakStatement struct {
+    Token token.Token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+func (bs *BreakStatement) TokenLiteral() string {
+    return bs.Token.Literal
+}
+
+func (bs *BreakStatement) Pos() token.Position {
+    return bs.Token.Pos
+}
+
+func (bs *BreakStatement) String() string {
+    return bs.Token.Literal
+}
+
+type ContinueStatement struct {
+    Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+func (cs *ContinueStatement) TokenLiteral() string {
+    return cs.Token.Literal
+}
+
+func (cs *ContinueStatement) Pos() token.Position {
+    return cs.Token.Pos
+}
+
+func (cs *ContinueStatement) String() string {
+    return cs.Token.Literal
+}
+
+type PassStatement struct {
+    Token token.Token
+}
+
+func (ps *PassStatement) statementNode() {}
+
+func (ps *PassStatement) TokenLiteral() string {
+    return ps.Token.Literal
+}
+
+func (ps *PassStatement) Pos() token.Position {
+    return ps.Token.Pos
+}
+
+func (ps *PassStatement) String() string {
+    return ps.Token.Literal
+}
+
+type TryStatement struct {
+    Token token.Token
+    Body *BlockStatement
+    Handlers []*ExceptClause
+    Alternative *BlockStatement
+    Finally *BlockStatement
+}
+
+func (ts *TryStatement) statementNode() {}
+
+func (ts *TryStatement) TokenLiteral() string {
+    return ts.Token.Literal
+}
+
+func (ts *TryStatement) Pos() token.Position {
+    return ts.Token.Pos
+}
+
+func (ts *TryStatement) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(")
+    out.WriteString(ts.Token.Literal + " ")
+    out.WriteString(ts.Body.String())
+
+    for _, handler := range ts.Handlers {
+        out.WriteString(" " + handler.String())
+    }
+
+    if ts.Alternative != nil {
+        out.WriteString(" else ")
+        out.WriteString(ts.Alternative.String())
+    }
+
+    if ts.Finally != nil {
+        out.WriteString(" finally ")
+        out.WriteString(ts.Finally.String())
+    }
+
+    out.WriteString(")")
+
+    return out.String()
+}
+
+// ExceptClause is a handler of a try statement. Class is nil for a bare
+// except catching everything, Name is nil unless the exception is bound.
+type ExceptClause struct {
+    Token token.Token
+    Class Expression
+    Name *Name
+    Body *BlockStatement
+}
+
+func (ec *ExceptClause) TokenLiteral() string {
+    return ec.Token.Literal
+}
+
+func (ec *ExceptClause) Pos() token.Position {
+    return ec.Token.Pos
+}
+
+func (ec *ExceptClause) String() string {
+    var out bytes.Buffer
+
+    out.WriteString(ec.Token.Literal)
+
+    if ec.Class != nil {
+        out.WriteString(" " + ec.Class.String())
+    }
+
+    if ec.Name != nil {
+        out.WriteString(" as " + ec.Name.String())
+    }
+
+    out.WriteString(" " + ec.Body.String())
+
+    return out.String()
+}
+
+type RaiseStatement struct {
+    Token token.Token
+    Value Expression
+}
+
+func (rs *RaiseStatement) statementNode() {}
+
+func (rs *RaiseStatement) TokenLiteral() string {
+    return rs.Token.Literal
+}
+
+func (rs *RaiseStatement) Pos() token.Position {
+    return rs.Token.Pos
+}
+
+func (rs *RaiseStatement) String() string {
+    if rs.Value == nil {
+        return rs.Token.Literal
+    }
+
+    return rs.Token.Literal + " " + rs.Value.String()
+}
+
 type BlockStatement struct {
     Token token.Token
     Statements []Statement
@@ -229,6 +621,10 @@ func (bs *BlockStatement) TokenLiteral() string {
     return bs.Token.Literal
 }
 
+func (bs *BlockStatement) Pos() token.Position {
+    return bs.Token.Pos
+}
+
 func (bs *BlockStatement) String() string {
     var out bytes.Buffer
 
@@ -242,8 +638,9 @@ func (bs *BlockStatement) String() string {
 type FunctionStatement struct {
     Token token.Token
     Name *Name
-    Arguments []*Name
+    Arguments []*Parameter
     Body *BlockStatement
+    Scope *Scope
 }
 
 func (fs *FunctionStatement) statementNode() {}
@@ -252,6 +649,10 @@ func (fs *FunctionStatement) TokenLiteral() string {
     return fs.Token.Literal
 }
 
+func (fs *FunctionStatement) Pos() token.Position {
+    return fs.Token.Pos
+}
+
 func (fs *FunctionStatement) String() string {
     var out bytes.Buffer
 
@@ -268,6 +669,164 @@ func (fs *FunctionStatement) String() string {
     return out.String()
 }
 
+// Scope describes the names of a function body: Locals are bound in the
+// function itself, Globals and Nonlocals are declared to refer to the
+// module or to an enclosing function.
+type Scope struct {
+    Locals map[string]bool
+    Globals map[string]bool
+    Nonlocals map[string]bool
+}
+
+func NewScope() *Scope {
+    return &Scope{
+        Locals: map[string]bool{},
+        Globals: map[string]bool{},
+        Nonlocals: map[string]bool{},
+    }
+}
+
+// GlobalStatement declares Names to refer to the module scope, or to the
+// nearest enclosing function scope when Nonlocal is set.
+type GlobalStatement struct {
+    Token token.Token
+    Names []*Name
+    Nonlocal bool
+}
+
+func (gs *GlobalStatement) statementNode() {}
+
+func (gs *GlobalStatement) TokenLiteral() string {
+    return gs.Token.Literal
+}
+
+func (gs *GlobalStatement) Pos() token.Position {
+    return gs.Token.Pos
+}
+
+func (gs *GlobalStatement) String() string {
+    names := []string{}
+
+    for _, name := range gs.Names {
+        names = append(names, name.String())
+    }
+
+    return gs.TokenLiteral() + " " + strings.Join(names, ", ")
+}
+
+type ParameterKind int
+
+const (
+    // PositionalParam can be passed by position or by keyword.
+    PositionalParam ParameterKind = iota
+    // KeywordOnlyParam follows *args or a bare * and is passed by keyword.
+    KeywordOnlyParam
+    // VarArgsParam collects the extra positional arguments (*args).
+    VarArgsParam
+    // VarKeywordParam collects the extra keyword arguments (**kwargs).
+    VarKeywordParam
+)
+
+// Parameter is a parameter of a function definition. Name is nil for the
+// bare * separating keyword-only parameters, Default is nil unless the
+// parameter has a default value.
+type Parameter struct {
+    Token token.Token
+    Name *Name
+    Default Expression
+    Kind ParameterKind
+}
+
+func (pm *Parameter) TokenLiteral() string {
+    return pm.Token.Literal
+}
+
+func (pm *Parameter) Pos() token.Position {
+    return pm.Token.Pos
+}
+
+func (pm *Parameter) String() string {
+    var out bytes.Buffer
+
+    switch pm.Kind {
+    case VarArgsParam:
+        out.WriteString("*")
+    case VarKeywordParam:
+        out.WriteString("**")
+    }
+
+    if pm.Name != nil {
+        out.WriteString(pm.Name.String())
+    }
+
+    if pm.Default != nil {
+        out.WriteString("=" + pm.Default.String())
+    }
+
+    return out.String()
+}
+
+// LambdaExpression is an anonymous function whose body is a single
+// expression.
+type LambdaExpression struct {
+    Token token.Token
+    Arguments []*Parameter
+    Body Expression
+    Scope *Scope
+}
+
+func (le *LambdaExpression) expressionNode() {}
+
+func (le *LambdaExpression) TokenLiteral() string {
+    return le.Token.Literal
+}
+
+func (le *LambdaExpression) Pos() token.Position {
+    return le.Token.Pos
+}
+
+func (le *LambdaExpression) String() string {
+    args := []string{}
+
+    for _, arg := range le.Arguments {
+        args = append(args, arg.String())
+    }
+
+    return "(lambda " + strings.Join(args, ", ") + ": " + le.Body.String() + ")"
+}
+
+// ClassStatement defines a class, Base is nil unless the class derives
+// from another one.
+type ClassStatement struct {
+    Token token.Token
+    Name *Name
+    Base Expression
+    Body *BlockStatement
+    Scope *Scope
+}
+
+func (cs *ClassStatement) statementNode() {}
+
+func (cs *ClassStatement) TokenLiteral() string {
+    return cs.Token.Literal
+}
+
+func (cs *ClassStatement) Pos() token.Position {
+    return cs.Token.Pos
+}
+
+func (cs *ClassStatement) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("class " + cs.Name.String())
+    if cs.Base != nil {
+        out.WriteString("(" + cs.Base.String() + ")")
+    }
+    out.WriteString(": " + cs.Body.String())
+
+    return out.String()
+}
+
 type PrefixExpression struct {
     Token token.Token
     Operator string
@@ -280,11 +839,21 @@ func (pe *PrefixExpression) TokenLiteral() string {
     return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position {
+    return pe.Token.Pos
+}
+
 func (pe *PrefixExpression) String() string {
     var out bytes.Buffer
 
     out.WriteString("(")
     out.WriteString(pe.Operator)
+
+    // Keyword operators are separated from their operand, e.g. "(not a)".
+    if pe.Operator == "not" {
+        out.WriteString(" ")
+    }
+
     out.WriteString(pe.Right.String())
     out.WriteString(")")
 
@@ -304,6 +873,10 @@ func (ie *InfixExpression) TokenLiteral() string {
     return ie.Token.Literal
 }
 
+func (ie *InfixExpression) Pos() token.Position {
+    return ie.Left.Pos()
+}
+
 func (ie *InfixExpression) String() string {
     var out bytes.Buffer
 
@@ -328,6 +901,10 @@ func (ce *CallExpression) TokenLiteral() string {
     return ce.Token.Literal
 }
 
+func (ce *CallExpression) Pos() token.Position {
+    return ce.Function.Pos()
+}
+
 func (ce *CallExpression) String() string {
     var out bytes.Buffer
 
@@ -343,6 +920,48 @@ func (ce *CallExpression) String() string {
     return out.String()
 }
 
+// KeywordArgument is a "name=value" argument of a call.
+type KeywordArgument struct {
+    Token token.Token
+    Name *Name
+    Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode() {}
+
+func (ka *KeywordArgument) TokenLiteral() string {
+    return ka.Token.Literal
+}
+
+func (ka *KeywordArgument) Pos() token.Position {
+    return ka.Token.Pos
+}
+
+func (ka *KeywordArgument) String() string {
+    return ka.Name.String() + "=" + ka.Value.String()
+}
+
+// AttributeExpression is an attribute reference "object.name".
+type AttributeExpression struct {
+    Token token.Token
+    Object Expression
+    Name *Name
+}
+
+func (ae *AttributeExpression) expressionNode() {}
+
+func (ae *AttributeExpression) TokenLiteral() string {
+    return ae.Token.Literal
+}
+
+func (ae *AttributeExpression) Pos() token.Position {
+    return ae.Object.Pos()
+}
+
+func (ae *AttributeExpression) String() string {
+    return ae.Object.String() + "." + ae.Name.String()
+}
+
 type ListLiteral struct {
     Token token.Token
     Arr []Expression
@@ -354,6 +973,10 @@ func (ll *ListLiteral) TokenLiteral() string {
     return ll.Token.Literal
 }
 
+func (ll *ListLiteral) Pos() token.Position {
+    return ll.Token.Pos
+}
+
 func (ll *ListLiteral) String() string {
     var out bytes.Buffer
 
@@ -368,6 +991,154 @@ func (ll *ListLiteral) String() string {
     return out.String()
 }
 
+// TupleLiteral is a parenthesized or bare comma separated list of
+// expressions, e.g. "(a, b)" or the target of "a, b = b, a".
+type TupleLiteral struct {
+    Token token.Token
+    Elements []Expression
+}
+
+func (tl *TupleLiteral) expressionNode() {}
+
+func (tl *TupleLiteral) TokenLiteral() string {
+    return tl.Token.Literal
+}
+
+func (tl *TupleLiteral) Pos() token.Position {
+    return tl.Token.Pos
+}
+
+func (tl *TupleLiteral) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("tuple((")
+
+    for _, elem := range tl.Elements {
+        out.WriteString(elem.String() + ", ")
+    }
+
+    out.WriteString("))")
+
+    return out.String()
+}
+
+type DictLiteral struct {
+    Token token.Token
+    Keys []Expression
+    Values []Expression
+}
+
+func (dl *DictLiteral) expressionNode() {}
+
+func (dl *DictLiteral) TokenLiteral() string {
+    return dl.Token.Literal
+}
+
+func (dl *DictLiteral) Pos() token.Position {
+    return dl.Token.Pos
+}
+
+func (dl *DictLiteral) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("dict({")
+
+    for i, key := range dl.Keys {
+        out.WriteString(key.String() + ": " + dl.Values[i].String() + ", ")
+    }
+
+    out.WriteString("})")
+
+    return out.String()
+}
+
+// SliceExpression is the subscript "start:stop:step" of an index
+// expression, each of its parts may be omitted.
+type SliceExpression struct {
+    Token token.Token
+    Start Expression
+    Stop Expression
+    Step Expression
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+func (se *SliceExpression) TokenLiteral() string {
+    return se.Token.Literal
+}
+
+func (se *SliceExpression) Pos() token.Position {
+    return se.Token.Pos
+}
+
+func (se *SliceExpression) String() string {
+    var out bytes.Buffer
+
+    for i, part := range []Expression{se.Start, se.Stop, se.Step} {
+        if i != 0 {
+            out.WriteString(":")
+        }
+
+        if part != nil {
+            out.WriteString(part.String())
+        }
+    }
+
+    return out.String()
+}
+
+// ComparisonExpression is a chain of comparisons, e.g. "a < b <= c". Each
+// operator compares the operands next to it and the chain holds if all of
+// them do.
+type ComparisonExpression struct {
+    Token token.Token
+    Operands []Expression
+    Operators []string
+}
+
+func (ce *ComparisonExpression) expressionNode() {}
+
+func (ce *ComparisonExpression) TokenLiteral() string {
+    return ce.Token.Literal
+}
+
+func (ce *ComparisonExpression) Pos() token.Position {
+    return ce.Operands[0].Pos()
+}
+
+func (ce *ComparisonExpression) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(" + ce.Operands[0].String())
+
+    for i, op := range ce.Operators {
+        out.WriteString(" " + op + " " + ce.Operands[i + 1].String())
+    }
+
+    out.WriteString(")")
+
+    return out.String()
+}
+
+// NoneLiteral is the None constant.
+type NoneLiteral struct {
+    Token token.Token
+}
+
+func (nl *NoneLiteral) expressionNode() {}
+
+func (nl *NoneLiteral) TokenLiteral() string {
+    return nl.Token.Literal
+}
+
+func (nl *NoneLiteral) Pos() token.Position {
+    return nl.Token.Pos
+}
+
+func (nl *NoneLiteral) String() string {
+    return "None"
+}
+
 type IndexExpression struct {
     Token token.Token
     Struct Expression
@@ -380,6 +1151,10 @@ func (ie *IndexExpression) TokenLiteral() string {
     return ie.Token.Literal
 }
 
+func (ie *IndexExpression) Pos() token.Position {
+    return ie.Struct.Pos()
+}
+
 func (ie *IndexExpression) String() string {
     var out bytes.Buffer
 
@@ -390,3 +1165,97 @@ func (ie *IndexExpression) String() string {
     return out.String()
 }
 
+
+// BytesLiteral is a b"..." literal, Value holds the decoded bytes.
+type BytesLiteral struct {
+    Token token.Token
+    Value string
+}
+
+func (bl *BytesLiteral) expressionNode() {}
+
+func (bl *BytesLiteral) TokenLiteral() string {
+    return bl.Token.Literal
+}
+
+func (bl *BytesLiteral) Pos() token.Position {
+    return bl.Token.Pos
+}
+
+func (bl *BytesLiteral) String() string {
+    return "b\"" + bl.Value + "\""
+}
+
+// FormattedString is an f-string, Parts are string literals and the
+// formatted values of the replacement fields, in order.
+type FormattedString struct {
+    Token token.Token
+    Parts []Expression
+}
+
+func (fs *FormattedString) expressionNode() {}
+
+func (fs *FormattedString) TokenLiteral() string {
+    return fs.Token.Literal
+}
+
+func (fs *FormattedString) Pos() token.Position {
+    return fs.Token.Pos
+}
+
+func (fs *FormattedString) String() string {
+    return "f\"" + fs.body() + "\""
+}
+
+func (fs *FormattedString) body() string {
+    var out bytes.Buffer
+
+    for _, part := range fs.Parts {
+        if literal, ok := part.(*StringLiteral); ok {
+            braces := strings.NewReplacer("{", "{{", "}", "}}")
+            out.WriteString(braces.Replace(literal.Value))
+        } else {
+            out.WriteString(part.String())
+        }
+    }
+
+    return out.String()
+}
+
+// FormattedValue is a replacement field of an f-string: Value is converted
+// with str(), repr() or ascii() if Conversion is "s", "r" or "a" and then
+// formatted with Spec, a nested f-string that may be nil.
+type FormattedValue struct {
+    Token token.Token
+    Value Expression
+    Conversion string
+    Spec *FormattedString
+}
+
+func (fv *FormattedValue) expressionNode() {}
+
+func (fv *FormattedValue) TokenLiteral() string {
+    return fv.Token.Literal
+}
+
+func (fv *FormattedValue) Pos() token.Position {
+    return fv.Value.Pos()
+}
+
+func (fv *FormattedValue) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("{" + fv.Value.String())
+
+    if fv.Conversion != "" {
+        out.WriteString("!" + fv.Conversion)
+    }
+
+    if fv.Spec != nil {
+        out.WriteString(":" + fv.Spec.body())
+    }
+
+    out.WriteString("}")
+
+    return out.String()
+}
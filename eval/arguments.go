@@ -0,0 +1,348 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/object"
+)
+
+// evalDefaults evaluates the default values of params, the result is
+// indexed like params and holds nil for parameters without a default.
+func evalDefaults(
+    params []*ast.Parameter, env *object.Env) ([]object.Object, object.Object) {
+
+    defaults := make([]object.Object, len(params))
+
+    for i, param := range params {
+        if param.Default == nil {
+            continue
+        }
+
+        val := Eval(param.Default, env)
+        if isError(val) {
+            return nil, val
+        }
+
+        defaults[i] = val
+    }
+
+    return defaults, nil
+}
+
+// evalCallArguments evaluates the arguments of a call, expanding *iterable
+// into positional arguments and **mapping into keyword arguments. The
+// keyword arguments are returned as a dict keyed by their names, which is
+// nil when there are none.
+func evalCallArguments(
+    exprs []ast.Expression,
+    env *object.Env) ([]object.Object, *object.Dict, *object.Error) {
+
+    args := []object.Object{}
+    var kwargs *object.Dict
+
+    setKeyword := func(name *object.String, val object.Object) *object.Error {
+        if kwargs == nil {
+            kwargs = object.NewDict()
+        }
+
+        if _, ok := kwargs.Get(name); ok {
+            return newError(
+                object.TypeError,
+                "got multiple values for keyword argument '%s'",
+                name.Value,
+            )
+        }
+
+        kwargs.Set(name, val)
+
+        return nil
+    }
+
+    for _, expr := range exprs {
+        var starred string
+
+        switch arg := expr.(type) {
+        case *ast.KeywordArgument:
+            val := Eval(arg.Value, env)
+            if err, ok := val.(*object.Error); ok {
+                return nil, nil, err
+            }
+
+            err := setKeyword(&object.String{Value: arg.Name.Value}, val)
+            if err != nil {
+                return nil, nil, err
+            }
+
+            continue
+        case *ast.PrefixExpression:
+            if arg.Operator == "*" || arg.Operator == "**" {
+                starred = arg.Operator
+                expr = arg.Right
+            }
+        }
+
+        val := Eval(expr, env)
+        if err, ok := val.(*object.Error); ok {
+            return nil, nil, err
+        }
+
+        switch starred {
+        case "*":
+            iter, err := getIterator(val)
+            if err != nil {
+                return nil, nil, newError(
+                    object.TypeError,
+                    "argument after * must be an iterable, not %s",
+                    val.Type(),
+                )
+            }
+
+            for item, ok := iter.Next(); ok; item, ok = iter.Next() {
+                if err, ok := item.(*object.Error); ok {
+                    return nil, nil, err
+                }
+
+                args = append(args, item)
+            }
+        case "**":
+            dict, ok := val.(*object.Dict)
+            if !ok {
+                return nil, nil, newError(
+                    object.TypeError,
+                    "argument after ** must be a mapping, not %s",
+                    val.Type(),
+                )
+            }
+
+            for _, pair := range dict.Pairs {
+
+                name, ok := pair.Key.(*object.String)
+                if !ok {
+                    return nil, nil, newError(
+                        object.TypeError, "keywords must be strings")
+                }
+
+                if err := setKeyword(name, pair.Value); err != nil {
+                    return nil, nil, err
+                }
+            }
+        default:
+            args = append(args, val)
+        }
+    }
+
+    return args, kwargs, nil
+}
+
+// bindArguments binds the arguments of a call to the parameters of
+// function in env, following Python's rules for matching positional and
+// keyword arguments to parameters.
+func bindArguments(
+    function *object.Function,
+    args []object.Object,
+    kwargs *object.Dict,
+    env *object.Env) object.Object {
+
+    var varArgs, varKeyword *ast.Parameter
+
+    positional := []int{}
+    bound := make([]bool, len(function.Arguments))
+
+    for i, param := range function.Arguments {
+        switch param.Kind {
+        case ast.PositionalParam:
+            positional = append(positional, i)
+        case ast.VarArgsParam:
+            varArgs = param
+        case ast.VarKeywordParam:
+            varKeyword = param
+        }
+    }
+
+    for i, arg := range args {
+        if i == len(positional) {
+            break
+        }
+
+        env.Set(function.Arguments[positional[i]].Name.Value, arg)
+        bound[positional[i]] = true
+    }
+
+    if len(args) > len(positional) {
+        if varArgs == nil || varArgs.Name == nil {
+            return tooManyPositional(function, len(positional), len(args))
+        }
+
+        extra := append([]object.Object{}, args[len(positional):]...)
+        env.Set(varArgs.Name.Value, &object.Tuple{Elements: extra})
+    } else if varArgs != nil && varArgs.Name != nil {
+        env.Set(varArgs.Name.Value, &object.Tuple{Elements: []object.Object{}})
+    }
+
+    extraKeywords := object.NewDict()
+
+    if kwargs != nil {
+        for _, pair := range kwargs.Pairs {
+            name := pair.Key.(*object.String).Value
+
+            idx := findKeywordParameter(function, name)
+
+            switch {
+            case idx >= 0 && bound[idx]:
+                return newError(
+                    object.TypeError,
+                    "%s() got multiple values for argument '%s'",
+                    function.Name,
+                    name,
+                )
+            case idx >= 0:
+                env.Set(name, pair.Value)
+                bound[idx] = true
+            case varKeyword != nil:
+                extraKeywords.Set(pair.Key.(*object.String), pair.Value)
+            default:
+                return newError(
+                    object.TypeError,
+                    "%s() got an unexpected keyword argument '%s'",
+                    function.Name,
+                    name,
+                )
+            }
+        }
+    }
+
+    if varKeyword != nil {
+        env.Set(varKeyword.Name.Value, extraKeywords)
+    }
+
+    return bindDefaults(function, bound, env)
+}
+
+// bindDefaults binds the defaults of the parameters that didn't get an
+// argument and reports the ones that are still missing.
+func bindDefaults(
+    function *object.Function, bound []bool, env *object.Env) object.Object {
+
+    missing := map[ast.ParameterKind][]string{}
+
+    for i, param := range function.Arguments {
+        if bound[i] || param.Name == nil {
+            continue
+        }
+
+        if param.Kind != ast.PositionalParam &&
+            param.Kind != ast.KeywordOnlyParam {
+            continue
+        }
+
+        if function.Defaults != nil && function.Defaults[i] != nil {
+            env.Set(param.Name.Value, function.Defaults[i])
+            continue
+        }
+
+        missing[param.Kind] = append(missing[param.Kind], param.Name.Value)
+    }
+
+    if names := missing[ast.PositionalParam]; len(names) != 0 {
+        return missingArguments(function, "positional", names)
+    }
+
+    if names := missing[ast.KeywordOnlyParam]; len(names) != 0 {
+        return missingArguments(function, "keyword-only", names)
+    }
+
+    return nil
+}
+
+// findKeywordParameter returns the index of the parameter of function that
+// can be passed by keyword as name, or -1 if there is none.
+func findKeywordParameter(function *object.Function, name string) int {
+    for i, param := range function.Arguments {
+        if param.Name == nil || param.Name.Value != name {
+            continue
+        }
+
+        if param.Kind == ast.PositionalParam ||
+            param.Kind == ast.KeywordOnlyParam {
+            return i
+        }
+    }
+
+    return -1
+}
+
+func tooManyPositional(
+    function *object.Function, accepted, given int) *object.Error {
+
+    required := accepted
+
+    for i, param := range function.Arguments {
+        if param.Kind != ast.PositionalParam || function.Defaults == nil {
+            continue
+        }
+
+        if function.Defaults[i] != nil {
+            required -= 1
+        }
+    }
+
+    takes := plural(accepted, "positional argument")
+    if required != accepted {
+        takes = fmt.Sprintf(
+            "from %d to %d positional arguments", required, accepted)
+    }
+
+    verb := "were"
+    if given == 1 {
+        verb = "was"
+    }
+
+    return newError(
+        object.TypeError,
+        "%s() takes %s but %d %s given",
+        function.Name,
+        takes,
+        given,
+        verb,
+    )
+}
+
+func missingArguments(
+    function *object.Function, kind string, names []string) *object.Error {
+
+    quoted := []string{}
+    for _, name := range names {
+        quoted = append(quoted, "'" + name + "'")
+    }
+
+    var list string
+
+    switch len(quoted) {
+    case 1:
+        list = quoted[0]
+    case 2:
+        list = quoted[0] + " and " + quoted[1]
+    default:
+        list = strings.Join(quoted[:len(quoted) - 1], ", ") +
+            ", and " + quoted[len(quoted) - 1]
+    }
+
+    return newError(
+        object.TypeError,
+        "%s() missing %s: %s",
+        function.Name,
+        plural(len(names), "required " + kind + " argument"),
+        list,
+    )
+}
+
+// plural formats a count of noun, e.g. "1 argument" or "2 arguments".
+func plural(n int, noun string) string {
+    if n == 1 {
+        return fmt.Sprintf("%d %s", n, noun)
+    }
+
+    return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -1,79 +1,722 @@
 package eval
 
 import (
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
 	"mxshs/pyinterpreter/object"
+	"mxshs/pyinterpreter/token"
 )
 
-var bltins = map[string]*object.Bltin{
-    "len": &object.Bltin{
-        Fn: pyLen,
-    },
-    "sum": &object.Bltin{
-        Fn: pySum,
-    },
+// Stdout is where print writes to.
+var Stdout io.Writer = os.Stdout
+
+// bltins are the builtin names. Most builtins call back into the
+// evaluator, so the map is filled in init to avoid an initialization cycle
+// through evalName.
+var bltins map[string]object.Object
+
+func init() {
+    bltins = map[string]object.Object{
+        "len": &object.Bltin{
+            Fn: pyLen,
+        },
+        "sum": &object.Bltin{
+            Fn: pySum,
+        },
+        "print": &object.Bltin{
+            Fn: pyPrint,
+        },
+        "range": &object.Bltin{
+            Fn: pyRange,
+        },
+        "list": &object.Bltin{
+            Fn: pyList,
+        },
+        "iter": &object.Bltin{
+            Fn: pyIter,
+        },
+        "next": &object.Bltin{
+            Fn: pyNext,
+        },
+        "super": &object.Bltin{
+            Fn: pySuper,
+        },
+        "str": &object.Bltin{
+            Fn: pyStr,
+        },
+        "bool": &object.Bltin{
+            Fn: pyBool,
+        },
+        "sorted": &object.Bltin{
+            KwFn: pySorted,
+        },
+        "map": &object.Bltin{
+            Fn: pyMap,
+        },
+        "slice": &object.Bltin{
+            Fn: pySlice,
+        },
+        "int": &object.Bltin{
+            Fn: pyInt,
+        },
+        "repr": &object.Bltin{
+            Fn: pyRepr,
+        },
+        "format": &object.Bltin{
+            Fn: pyFormat,
+        },
+        "NotImplemented": NOT_IMPLEMENTED,
+    }
+
+    for name, bltin := range bltins {
+        if bltin, ok := bltin.(*object.Bltin); ok {
+            bltin.Name = name
+        }
+    }
+
+    for _, class := range object.ExceptionClasses {
+        bltins[class.Name] = class
+    }
 }
 
 func pyLen(args ...object.Object) object.Object {
     if len(args) != 1 {
         return newError(
+            object.TypeError,
             "expected 1 sequence-like argument, got %d arguments",
             len(args),
         )
     }
 
-    switch arg := args[0].(type) {
-    case *object.String:
-        return &object.Integer{
-            Value: int64(len(arg.Value)),
+    if res, ok := callMethod(args[0], "__len__"); ok {
+        return checkLength(res)
+    }
+
+    if r, ok := args[0].(*object.Range); ok {
+        length, ok := r.Length()
+        if !ok {
+            return newError(
+                object.OverflowError,
+                "Python int too large to convert to C ssize_t",
+            )
         }
-    case *object.List:
-        return &object.Integer{
-            Value: int64(len(arg.Arr)),
+
+        return &object.Integer{Value: length}
+    }
+
+    sized, ok := args[0].(object.Sized)
+    if !ok {
+        if isInstance(args[0]) {
+            return newError(
+                object.TypeError,
+                "object of type '%s' has no len()",
+                typeName(args[0]),
+            )
         }
-    default:
+
         return newError(
+            object.TypeError,
             "expected sequence-like argument, got %s argument",
             args[0].Type(),
         )
     }
+
+    return &object.Integer{
+        Value: int64(sized.Len()),
+    }
 }
 
 func pySum(args ...object.Object) object.Object {
+    if len(args) != 1 && len(args) != 2 {
+        return newError(
+            object.TypeError,
+            "expected an iterable and an optional start, got %d arguments",
+            len(args),
+        )
+    }
+
+    iterator, err := getIterator(args[0])
+    if err != nil {
+        return err
+    }
+
+    var res object.Object = &object.Integer{Value: 0}
+    if len(args) == 2 {
+        res = args[1]
+    }
+
+    for {
+        item, ok := iterator.Next()
+        if !ok {
+            return res
+        }
+
+        if isError(item) {
+            return item
+        }
+
+        res = evalInfixExpression("+", res, item)
+        if isError(res) {
+            return res
+        }
+    }
+}
+
+func pyRange(args ...object.Object) object.Object {
+    if len(args) < 1 || len(args) > 3 {
+        return newError(
+            object.TypeError,
+            "expected 1 to 3 integer arguments, got %d arguments",
+            len(args),
+        )
+    }
+
+    bounds := []int64{}
+    for _, arg := range args {
+        integer, ok := arg.(*object.Integer)
+        if !ok {
+            return newError(
+                object.TypeError,
+                "expected integer argument, got %s argument",
+                arg.Type(),
+            )
+        }
+
+        if integer.Big != nil {
+            return newError(
+                object.OverflowError,
+                "Python int too large to convert to C ssize_t",
+            )
+        }
+
+        bounds = append(bounds, integer.Value)
+    }
+
+    r := &object.Range{Step: 1}
+
+    switch len(bounds) {
+    case 1:
+        r.Stop = bounds[0]
+    case 2:
+        r.Start, r.Stop = bounds[0], bounds[1]
+    case 3:
+        r.Start, r.Stop, r.Step = bounds[0], bounds[1], bounds[2]
+    }
+
+    if r.Step == 0 {
+        return newError(object.ValueError, "range() arg 3 must not be zero")
+    }
+
+    return r
+}
+
+func pyList(args ...object.Object) object.Object {
+    if len(args) == 0 {
+        return &object.List{Arr: []object.Object{}}
+    }
+
+    if len(args) != 1 {
+        return newError(
+            object.TypeError,
+            "expected at most 1 iterable argument, got %d arguments",
+            len(args),
+        )
+    }
+
+    iterator, err := getIterator(args[0])
+    if err != nil {
+        return err
+    }
+
+    elements := []object.Object{}
+    for {
+        item, ok := iterator.Next()
+        if !ok {
+            return &object.List{Arr: elements}
+        }
+
+        if isError(item) {
+            return item
+        }
+
+        elements = append(elements, item)
+    }
+}
+
+func pyIter(args ...object.Object) object.Object {
+    if len(args) != 1 {
+        return newError(
+            object.TypeError,
+            "expected 1 iterable argument, got %d arguments",
+            len(args),
+        )
+    }
+
+    iterator, err := getIterator(args[0])
+    if err != nil {
+        return err
+    }
+
+    return iterator
+}
+
+func pyNext(args ...object.Object) object.Object {
+    if len(args) != 1 && len(args) != 2 {
+        return newError(
+            object.TypeError,
+            "expected an iterator and an optional default, got %d arguments",
+            len(args),
+        )
+    }
+
+    if res, ok := callMethod(args[0], "__next__"); ok {
+        err, isErr := res.(*object.Error)
+        if isErr && err.Class.IsSubclass(object.StopIteration) && len(args) == 2 {
+            return args[1]
+        }
+
+        return res
+    }
+
+    iterator, ok := args[0].(object.Iterator)
+    if !ok {
+        return newError(
+            object.TypeError,
+            "'%s' object is not an iterator",
+            args[0].Type(),
+        )
+    }
+
+    item, ok := iterator.Next()
+    if !ok {
+        if len(args) == 2 {
+            return args[1]
+        }
+
+        return newError(object.StopIteration, "")
+    }
+
+    return item
+}
+
+func pyPrint(args ...object.Object) object.Object {
+    out := []string{}
+
+    for _, arg := range args {
+        str := toStr(arg)
+        if isError(str) {
+            return str
+        }
+
+        out = append(out, str.Inspect())
+    }
+
+    io.WriteString(Stdout, strings.Join(out, " ") + "\n")
+
+    return NULL
+}
+
+// checkLength validates the result of __len__.
+func checkLength(res object.Object) object.Object {
+    length, ok := res.(*object.Integer)
+
     switch {
+    case isError(res):
+        return res
+    case !ok:
+        return newError(
+            object.TypeError,
+            "'%s' object cannot be interpreted as an integer",
+            typeName(res),
+        )
+    case length.Value < 0 || (length.Big != nil && length.Big.Sign() < 0):
+        return newError(object.ValueError, "__len__() should return >= 0")
+    case length.Big != nil:
+        return newError(
+            object.OverflowError,
+            "cannot fit 'int' into an index-sized integer",
+        )
     default:
-        var s1 *float64
-        var s2 *int64
-        temp := int64(0)
-        s2 = &temp
-
-        if _, ok := args[0].(*object.List); ok {
-            args = args[0].(*object.List).Arr
-        }
-
-        for _, obj := range args {
-            if obj.Type() == object.INTEGER_OBJ {
-                if s1 != nil {
-                    *s1 += float64(obj.(*object.Integer).Value)
-                } else {
-                    *s2 += obj.(*object.Integer).Value
-                }
-            } else {
-                if s1 == nil {
-                    temp := float64(*s2)
-                    s1 = &temp
-                }
-                *s1 += obj.(*object.Float).Value
-            }
+        return length
+    }
+}
+
+func pyStr(args ...object.Object) object.Object {
+    switch len(args) {
+    case 0:
+        return &object.String{Value: ""}
+    case 1:
+        return toStr(args[0])
+    default:
+        return newError(
+            object.TypeError,
+            "str expected at most 1 argument, got %d",
+            len(args),
+        )
+    }
+}
+
+func pyRepr(args ...object.Object) object.Object {
+    if len(args) != 1 {
+        return newError(
+            object.TypeError,
+            "repr() takes exactly one argument (%d given)",
+            len(args),
+        )
+    }
+
+    return toRepr(args[0])
+}
+
+// pyFormat formats a value with an optional format spec, as a replacement
+// field of an f-string does.
+func pyFormat(args ...object.Object) object.Object {
+    if len(args) != 1 && len(args) != 2 {
+        return newError(
+            object.TypeError,
+            "format expected 1 or 2 arguments, got %d",
+            len(args),
+        )
+    }
+
+    spec := ""
+    if len(args) == 2 {
+        str, ok := args[1].(*object.String)
+        if !ok {
+            return newError(
+                object.TypeError,
+                "format() argument 2 must be str, not %s",
+                typeName(args[1]),
+            )
+        }
+
+        spec = str.Value
+    }
+
+    return formatValue(args[0], spec)
+}
+
+func pyBool(args ...object.Object) object.Object {
+    switch len(args) {
+    case 0:
+        return FALSE
+    case 1:
+        return truthValue(args[0])
+    default:
+        return newError(
+            object.TypeError,
+            "bool expected at most 1 argument, got %d",
+            len(args),
+        )
+    }
+}
+
+// pyInt converts a number or a string to an int, strings are parsed in
+// base 10 or in the given base.
+func pyInt(args ...object.Object) object.Object {
+    if len(args) > 2 {
+        return newError(
+            object.TypeError,
+            "int expected at most 2 arguments, got %d",
+            len(args),
+        )
+    }
+
+    if len(args) == 0 {
+        return &object.Integer{Value: 0}
+    }
+
+    if len(args) == 2 {
+        str, ok := args[0].(*object.String)
+        if !ok {
+            return newError(
+                object.TypeError,
+                "int() can't convert non-string with explicit base",
+            )
+        }
+
+        base, ok := args[1].(*object.Integer)
+        if !ok {
+            return newError(
+                object.TypeError,
+                "'%s' object cannot be interpreted as an integer",
+                typeName(args[1]),
+            )
+        }
+
+        return parseIntArgument(str.Value, base)
+    }
+
+    switch arg := args[0].(type) {
+    case *object.Integer:
+        return arg
+    case *object.Boolean:
+        if arg.Value {
+            return &object.Integer{Value: 1}
+        }
+
+        return &object.Integer{Value: 0}
+    case *object.Float:
+        switch {
+        case math.IsNaN(arg.Value):
+            return newError(
+                object.ValueError,
+                "cannot convert float NaN to integer",
+            )
+        case math.IsInf(arg.Value, 0):
+            return newError(
+                object.OverflowError,
+                "cannot convert float infinity to integer",
+            )
         }
 
-        if s1 != nil {
-            return &object.Float{
-                Value: *s1,
+        return floatToInt(arg.Value)
+    case *object.String:
+        return parseIntArgument(arg.Value, &object.Integer{Value: 10})
+    }
+
+    if res, ok := callMethod(args[0], "__int__"); ok {
+        if _, ok := res.(*object.Integer); !ok && !isError(res) {
+            return newError(
+                object.TypeError,
+                "__int__ returned non-int (type %s)",
+                typeName(res),
+            )
+        }
+
+        return res
+    }
+
+    return newError(
+        object.TypeError,
+        "int() argument must be a string, a bytes-like object or a real number, not '%s'",
+        typeName(args[0]),
+    )
+}
+
+func parseIntArgument(s string, base *object.Integer) object.Object {
+    if base.Big != nil || (base.Value != 0 && (base.Value < 2 || base.Value > 36)) {
+        return newError(
+            object.ValueError,
+            "int() base must be >= 2 and <= 36, or 0",
+        )
+    }
+
+    res, ok := parseInt(s, int(base.Value))
+    if !ok {
+        return newError(
+            object.ValueError,
+            "invalid literal for int() with base %d: '%s'",
+            base.Value,
+            s,
+        )
+    }
+
+    return res
+}
+
+// pySlice returns a slice object, as used by "xs[start:stop:step]".
+func pySlice(args ...object.Object) object.Object {
+    switch len(args) {
+    case 1:
+        return &object.Slice{Start: NULL, Stop: args[0], Step: NULL}
+    case 2:
+        return &object.Slice{Start: args[0], Stop: args[1], Step: NULL}
+    case 3:
+        return &object.Slice{Start: args[0], Stop: args[1], Step: args[2]}
+    default:
+        return newError(
+            object.TypeError,
+            "slice expected 1 to 3 arguments, got %d",
+            len(args),
+        )
+    }
+}
+
+// callFunction calls function on behalf of the running builtin.
+func callFunction(function object.Object, args ...object.Object) object.Object {
+    return runFunction(function, args, nil, callSite)
+}
+
+// keywordArguments checks that kwargs only holds the keywords allowed by
+// the builtin name and returns them by keyword.
+func keywordArguments(
+    name string,
+    kwargs *object.Dict,
+    allowed ...string) (map[string]object.Object, *object.Error) {
+
+    res := map[string]object.Object{}
+    if kwargs == nil {
+        return res, nil
+    }
+
+    for _, pair := range kwargs.Pairs {
+        keyword := pair.Key.(*object.String).Value
+
+        found := false
+        for _, allowedKeyword := range allowed {
+            found = found || allowedKeyword == keyword
+        }
+
+        if !found {
+            return nil, newError(
+                object.TypeError,
+                "'%s' is an invalid keyword argument for %s()",
+                keyword,
+                name,
+            )
+        }
+
+        res[keyword] = pair.Value
+    }
+
+    return res, nil
+}
+
+// pySorted returns a new sorted list of the items of an iterable, ordered
+// with "<" on the items or on the results of the key function.
+func pySorted(args []object.Object, kwargs *object.Dict) object.Object {
+    if len(args) != 1 {
+        return newError(
+            object.TypeError,
+            "sorted expected 1 argument, got %d",
+            len(args),
+        )
+    }
+
+    keywords, err := keywordArguments("sorted", kwargs, "key", "reverse")
+    if err != nil {
+        return err
+    }
+
+    list := pyList(args[0])
+    if isError(list) {
+        return list
+    }
+
+    items := list.(*object.List).Arr
+    keys := items
+
+    if key, ok := keywords["key"]; ok && key != NULL {
+        keys = make([]object.Object, len(items))
+
+        for i, item := range items {
+            keys[i] = callFunction(key, item)
+            if isError(keys[i]) {
+                return keys[i]
             }
         }
+    }
+
+    reverse := false
+    if flag, ok := keywords["reverse"]; ok {
+        reverse, err = checkCondition(flag)
+        if err != nil {
+            return err
+        }
+    }
+
+    order := make([]int, len(items))
+    for i := range order {
+        order[i] = i
+    }
+
+    var failed object.Object
 
-        return &object.Integer{
-            Value: *s2,
+    // The order is stable even when reversed, like in Python.
+    sort.SliceStable(order, func(i, j int) bool {
+        left, right := keys[order[i]], keys[order[j]]
+        if reverse {
+            left, right = right, left
         }
+
+        res := evalInfixExpression("<", left, right)
+        if isError(res) && failed == nil {
+            failed = res
+        }
+
+        return res == TRUE
+    })
+
+    if failed != nil {
+        return failed
     }
+
+    sorted := make([]object.Object, len(items))
+    for i, idx := range order {
+        sorted[i] = items[idx]
+    }
+
+    return &object.List{Arr: sorted}
+}
+
+// pyMap returns an iterator calling a function on the items of the given
+// iterables, it stops with the shortest of them.
+func pyMap(args ...object.Object) object.Object {
+    if len(args) < 2 {
+        return newError(
+            object.TypeError,
+            "map() must have at least two arguments.",
+        )
+    }
+
+    iterators := []object.Iterator{}
+
+    for _, arg := range args[1:] {
+        iterator, err := getIterator(arg)
+        if err != nil {
+            return err
+        }
+
+        iterators = append(iterators, iterator)
+    }
+
+    return &mapIterator{
+        function: args[0],
+        iterators: iterators,
+        callPos: callSite,
+    }
+}
+
+// mapIterator is the lazy result of map, callPos is where map was called
+// as the function is called later on, while the iterator is consumed.
+type mapIterator struct {
+    function object.Object
+    iterators []object.Iterator
+    callPos token.Position
+}
+
+func (mi *mapIterator) Type() object.ObjectType {
+    return object.ITERATOR_OBJ
+}
+
+func (mi *mapIterator) Inspect() string {
+    return "map object"
+}
+
+func (mi *mapIterator) Iter() object.Iterator {
+    return mi
+}
+
+func (mi *mapIterator) Next() (object.Object, bool) {
+    args := []object.Object{}
+
+    for _, iterator := range mi.iterators {
+        item, ok := iterator.Next()
+        if !ok {
+            return nil, false
+        }
+
+        if isError(item) {
+            return item, true
+        }
+
+        args = append(args, item)
+    }
+
+    return runFunction(mi.function, args, nil, mi.callPos), true
 }
@@ -0,0 +1,272 @@
+package eval
+
+import (
+	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/object"
+	"mxshs/pyinterpreter/token"
+)
+
+// evalClassStatement runs the body of a class in a namespace of its own
+// and binds the class created from that namespace.
+func evalClassStatement(cs *ast.ClassStatement, env *object.Env) object.Object {
+    var base object.Object
+
+    if cs.Base != nil {
+        base = Eval(cs.Base, env)
+        if isError(base) {
+            return base
+        }
+    }
+
+    classEnv := object.NewClassEnv(env, cs.Scope)
+
+    evaluated := Eval(cs.Body, classEnv)
+    if isError(evaluated) {
+        return evaluated
+    }
+
+    attrs := classEnv.Store()
+
+    switch base := base.(type) {
+    case nil:
+        env.Set(cs.Name.Value, &object.Class{Name: cs.Name.Value, Attrs: attrs})
+    case *object.Class:
+        env.Set(
+            cs.Name.Value,
+            &object.Class{Name: cs.Name.Value, Base: base, Attrs: attrs},
+        )
+    case *object.ExceptionClass:
+        env.Set(
+            cs.Name.Value,
+            &object.ExceptionClass{Name: cs.Name.Value, Base: base, Attrs: attrs},
+        )
+    default:
+        return locate(
+            newError(
+                object.TypeError,
+                "base class must be a class, not %s",
+                base.Type(),
+            ),
+            cs.Base,
+        )
+    }
+
+    return NULL
+}
+
+// newInstance creates an instance of class and initializes it with
+// __init__ if the class has one.
+func newInstance(
+    class *object.Class,
+    args []object.Object,
+    kwargs *object.Dict,
+    callPos token.Position) object.Object {
+
+    instance := object.NewInstance(class)
+
+    init, ok := class.Lookup("__init__")
+    if !ok {
+        if len(args) != 0 || kwargs != nil {
+            return newError(
+                object.TypeError,
+                "%s() takes no arguments",
+                class.Name,
+            )
+        }
+
+        return instance
+    }
+
+    res := runFunction(
+        bindMethod(init, instance),
+        args,
+        kwargs,
+        callPos,
+    )
+    if isError(res) {
+        return res
+    }
+
+    if res != NULL {
+        return newError(
+            object.TypeError,
+            "__init__() should return None, not '%s'",
+            res.Type(),
+        )
+    }
+
+    return instance
+}
+
+// bindMethod binds attr, looked up in the class of self, to self if it is
+// a function.
+func bindMethod(attr, self object.Object) object.Object {
+    if function, ok := attr.(*object.Function); ok {
+        return &object.BoundMethod{Self: self, Function: function}
+    }
+
+    return attr
+}
+
+// instanceOf returns the attributes and the class of obj if it is an
+// instance of a user defined class or an exception.
+func instanceOf(obj object.Object) (map[string]object.Object, object.ClassObject, bool) {
+    switch obj := obj.(type) {
+    case *object.Instance:
+        return obj.Attrs, obj.Class, true
+    case *object.ExceptionInstance:
+        return obj.Attrs, obj.Class, true
+    }
+
+    return nil, nil, false
+}
+
+func getAttribute(obj object.Object, name string) object.Object {
+    if attrs, class, ok := instanceOf(obj); ok {
+        if attr, ok := attrs[name]; ok {
+            return attr
+        }
+
+        if attr, ok := object.Lookup(class, name); ok {
+            return bindMethod(attr, obj)
+        }
+
+        return newError(
+            object.AttributeError,
+            "'%s' object has no attribute '%s'",
+            class.ClassName(),
+            name,
+        )
+    }
+
+    switch obj := obj.(type) {
+    case object.ClassObject:
+        if attr, ok := object.Lookup(obj, name); ok {
+            return attr
+        }
+
+        return newError(
+            object.AttributeError,
+            "type object '%s' has no attribute '%s'",
+            obj.ClassName(),
+            name,
+        )
+    case *object.Super:
+        if base := obj.Class.Parent(); base != nil {
+            if attr, ok := object.Lookup(base, name); ok {
+                return bindMethod(attr, obj.Self)
+            }
+        }
+
+        // The builtin exception classes only define __init__.
+        if exception, ok := obj.Self.(*object.ExceptionInstance); ok && name == "__init__" {
+            return exceptionInit(exception)
+        }
+
+        return newError(
+            object.AttributeError,
+            "'super' object has no attribute '%s'",
+            name,
+        )
+    }
+
+    return newError(
+        object.AttributeError,
+        "'%s' object has no attribute '%s'",
+        typeName(obj),
+        name,
+    )
+}
+
+func setAttribute(obj object.Object, name string, val object.Object) object.Object {
+    switch obj := obj.(type) {
+    case *object.Instance:
+        obj.Attrs[name] = val
+    case *object.Class:
+        obj.Attrs[name] = val
+    case *object.ExceptionInstance:
+        obj.Attrs[name] = val
+    default:
+        return newError(
+            object.AttributeError,
+            "'%s' object has no attribute '%s'",
+            typeName(obj),
+            name,
+        )
+    }
+
+    return NULL
+}
+
+// typeName is the name of the type of obj as shown in error messages.
+func typeName(obj object.Object) string {
+    if _, class, ok := instanceOf(obj); ok {
+        return class.ClassName()
+    }
+
+    return string(obj.Type())
+}
+
+// pySuper returns a proxy looking up attributes in the bases of a class.
+// Without arguments the class is the one defining the running method and
+// the instance is the method's first argument.
+func pySuper(args ...object.Object) object.Object {
+    switch len(args) {
+    case 0:
+        return currentSuper()
+    case 2:
+        if class, ok := args[0].(object.ClassObject); ok {
+            return &object.Super{Class: class, Self: args[1]}
+        }
+
+        return newError(
+            object.TypeError,
+            "super() argument 1 must be a type, not %s",
+            args[0].Type(),
+        )
+    default:
+        return newError(
+            object.TypeError,
+            "super() takes 0 or 2 arguments, got %d",
+            len(args),
+        )
+    }
+}
+
+func currentSuper() object.Object {
+    if len(frames) == 0 || len(frames[len(frames) - 1].Function.Arguments) == 0 {
+        return newError(object.RuntimeError, "super(): no arguments")
+    }
+
+    frame := frames[len(frames) - 1]
+
+    param := frame.Function.Arguments[0]
+    if param.Name == nil || param.Kind != ast.PositionalParam {
+        return newError(object.RuntimeError, "super(): no arguments")
+    }
+
+    self, ok := frame.Env.Get(param.Name.Value)
+    if !ok {
+        return newError(object.RuntimeError, "super(): arg[0] deleted")
+    }
+
+    // The class defining the method is the one whose body bound it.
+    _, class, ok := instanceOf(self)
+    for ; ok && class != nil; class = class.Parent() {
+        if definesFunction(class.Namespace(), frame.Function) {
+            return &object.Super{Class: class, Self: self}
+        }
+    }
+
+    return newError(object.RuntimeError, "super(): __class__ cell not found")
+}
+
+func definesFunction(attrs map[string]object.Object, function *object.Function) bool {
+    for _, attr := range attrs {
+        if attr == function {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,136 @@
+package eval
+
+import (
+	"math"
+	"math/cmplx"
+
+	"mxshs/pyinterpreter/object"
+)
+
+func isComplex(obj object.Object) bool {
+    return obj.Type() == object.COMPLEX_OBJ
+}
+
+// toComplex promotes an int, a float or a complex number to a complex
+// number.
+func toComplex(op string, obj object.Object) (complex128, *object.Error) {
+    switch obj := obj.(type) {
+    case *object.Complex:
+        return obj.Value, nil
+    case *object.Float:
+        return complex(obj.Value, 0), nil
+    default:
+        f, err := intToFloat(op, obj.(*object.Integer))
+        if err != nil {
+            return 0, err
+        }
+
+        return complex(f.Value, 0), nil
+    }
+}
+
+// evalComplexInfixExpression applies an operator to two numbers at least
+// one of which is complex. Complex numbers have no ordering, floor
+// division or modulo.
+func evalComplexInfixExpression(
+    op string, left, right object.Object) object.Object {
+
+    switch op {
+    case "+", "-", "*", "/", "**", "==", "!=":
+    case "<", ">", "<=", ">=":
+        return newError(
+            object.TypeError,
+            "'%s' not supported between instances of '%s' and '%s'",
+            op,
+            typeName(left),
+            typeName(right),
+        )
+    default:
+        return newError(
+            object.TypeError,
+            "unsupported operand type(s) for %s: '%s' and '%s'",
+            op,
+            typeName(left),
+            typeName(right),
+        )
+    }
+
+    leftVal, err := toComplex(op, left)
+    if err != nil {
+        return err
+    }
+
+    rightVal, err := toComplex(op, right)
+    if err != nil {
+        return err
+    }
+
+    switch op {
+    case "+":
+        return &object.Complex{Value: leftVal + rightVal}
+    case "-":
+        return &object.Complex{Value: leftVal - rightVal}
+    case "*":
+        return &object.Complex{Value: leftVal * rightVal}
+    case "/":
+        if rightVal == 0 {
+            return newError(object.ZeroDivisionError, "complex division by zero")
+        }
+
+        return &object.Complex{Value: leftVal / rightVal}
+    case "**":
+        if leftVal == 0 && (real(rightVal) < 0 || imag(rightVal) != 0) {
+            return newError(
+                object.ZeroDivisionError,
+                "0.0 to a negative or complex power",
+            )
+        }
+
+        return &object.Complex{Value: complexPow(leftVal, rightVal)}
+    case "==":
+        return nativeBoolToBoolean(leftVal == rightVal)
+    default:
+        return nativeBoolToBoolean(leftVal != rightVal)
+    }
+}
+
+// complexPow raises a to the power b, small integral powers are computed by
+// squaring so that 2j ** 2 is exactly -4. Other powers are computed from
+// the polar form of a the way CPython does, which rounds differently than
+// cmplx.Pow.
+func complexPow(a, b complex128) complex128 {
+    n := real(b)
+    if imag(b) != 0 || n != math.Trunc(n) || math.Abs(n) > 100 {
+        if a == 0 {
+            return 0
+        }
+
+        vabs := cmplx.Abs(a)
+        length := math.Pow(vabs, real(b))
+        angle := math.Atan2(imag(a), real(a))
+        phase := angle * real(b)
+
+        if imag(b) != 0 {
+            length /= math.Exp(angle * imag(b))
+            phase += imag(b) * math.Log(vabs)
+        }
+
+        return complex(length * math.Cos(phase), length * math.Sin(phase))
+    }
+
+    res, exp := complex128(1), int(math.Abs(n))
+    for exp > 0 {
+        if exp & 1 != 0 {
+            res *= a
+        }
+
+        a *= a
+        exp >>= 1
+    }
+
+    if n < 0 {
+        return 1 / res
+    }
+
+    return res
+}
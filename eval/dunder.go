@@ -0,0 +1,294 @@
+package eval
+
+import (
+	"mxshs/pyinterpreter/object"
+)
+
+// binaryMethods maps binary operators to the dunder method of the left
+// operand and the reflected one of the right operand.
+var binaryMethods = map[string][2]string{
+    "+": {"__add__", "__radd__"},
+    "-": {"__sub__", "__rsub__"},
+    "*": {"__mul__", "__rmul__"},
+    "/": {"__truediv__", "__rtruediv__"},
+    "//": {"__floordiv__", "__rfloordiv__"},
+    "%": {"__mod__", "__rmod__"},
+    "**": {"__pow__", "__rpow__"},
+    "&": {"__and__", "__rand__"},
+    "|": {"__or__", "__ror__"},
+    "^": {"__xor__", "__rxor__"},
+    "<<": {"__lshift__", "__rlshift__"},
+    ">>": {"__rshift__", "__rrshift__"},
+    "==": {"__eq__", "__eq__"},
+    "!=": {"__ne__", "__ne__"},
+    "<": {"__lt__", "__gt__"},
+    ">": {"__gt__", "__lt__"},
+    "<=": {"__le__", "__ge__"},
+    ">=": {"__ge__", "__le__"},
+}
+
+// inplaceMethods maps the operators of augmented assignments to the dunder
+// methods updating the left operand in place.
+var inplaceMethods = map[string]string{
+    "+": "__iadd__",
+    "-": "__isub__",
+    "*": "__imul__",
+    "/": "__itruediv__",
+    "//": "__ifloordiv__",
+    "%": "__imod__",
+    "**": "__ipow__",
+    "&": "__iand__",
+    "|": "__ior__",
+    "^": "__ixor__",
+    "<<": "__ilshift__",
+    ">>": "__irshift__",
+}
+
+// callMethod calls the dunder method name of obj, which is looked up on
+// its class. It reports false if obj is not an instance defining it.
+func callMethod(
+    obj object.Object, name string, args ...object.Object) (object.Object, bool) {
+
+    instance, ok := obj.(*object.Instance)
+    if !ok {
+        return nil, false
+    }
+
+    method, ok := instance.Class.Lookup(name)
+    if !ok {
+        return nil, false
+    }
+
+    return runFunction(bindMethod(method, instance), args, nil, callSite), true
+}
+
+func isInstance(obj object.Object) bool {
+    _, ok := obj.(*object.Instance)
+    return ok
+}
+
+// evalInstanceInfixExpression applies a binary operator with at least one
+// instance operand. The method of the left operand is tried first, then the
+// reflected one of the right operand, NotImplemented passes to the next.
+func evalInstanceInfixExpression(
+    op string, left, right object.Object) object.Object {
+
+    if methods, ok := binaryMethods[op]; ok {
+        res, ok := callMethod(left, methods[0], right)
+        if ok && res != NOT_IMPLEMENTED {
+            return res
+        }
+
+        res, ok = callMethod(right, methods[1], left)
+        if ok && res != NOT_IMPLEMENTED {
+            return res
+        }
+    }
+
+    switch op {
+    case "==":
+        return nativeBoolToBoolean(left == right)
+    case "!=":
+        // Without __ne__ the result of __eq__ is inverted.
+        res := evalInstanceInfixExpression("==", left, right)
+        if isError(res) {
+            return res
+        }
+
+        return evalBangOperatorExpression(res)
+    case "<", ">", "<=", ">=":
+        return newError(
+            object.TypeError,
+            "'%s' not supported between instances of '%s' and '%s'",
+            op,
+            typeName(left),
+            typeName(right),
+        )
+    default:
+        return newError(
+            object.TypeError,
+            "unsupported operand type(s) for %s: '%s' and '%s'",
+            op,
+            typeName(left),
+            typeName(right),
+        )
+    }
+}
+
+// evalInplaceExpression applies the operator of an augmented assignment.
+// The left operand is updated in place if it supports it, otherwise the
+// binary operator is applied.
+func evalInplaceExpression(op string, left, right object.Object) object.Object {
+    if res, ok := callMethod(left, inplaceMethods[op], right); ok {
+        if res != NOT_IMPLEMENTED {
+            return res
+        }
+    }
+
+    if list, ok := left.(*object.List); ok && op == "+" {
+        return extendList(list, right)
+    }
+
+    return evalInfixExpression(op, left, right)
+}
+
+// truthValue returns the truth of obj as a boolean.
+func truthValue(obj object.Object) object.Object {
+    truth, err := checkCondition(obj)
+    if err != nil {
+        return err
+    }
+
+    return nativeBoolToBoolean(truth)
+}
+
+// instanceTruth tests the truth of an instance, dispatching to __bool__ and
+// then to __len__. Instances defining neither are true.
+func instanceTruth(instance *object.Instance) (bool, *object.Error) {
+    if res, ok := callMethod(instance, "__bool__"); ok {
+        switch res {
+        case TRUE:
+            return true, nil
+        case FALSE:
+            return false, nil
+        }
+
+        if err, ok := res.(*object.Error); ok {
+            return false, err
+        }
+
+        return false, newError(
+            object.TypeError,
+            "__bool__ should return bool, returned %s",
+            typeName(res),
+        )
+    }
+
+    if _, ok := instance.Class.Lookup("__len__"); !ok {
+        return true, nil
+    }
+
+    length := pyLen(instance)
+    if err, ok := length.(*object.Error); ok {
+        return false, err
+    }
+
+    return length.(*object.Integer).Value != 0, nil
+}
+
+// toStr converts obj to a string, dispatching to __str__ and then to
+// __repr__ for instances.
+func toStr(obj object.Object) object.Object {
+    if res, ok := callStringMethod(obj, "__str__", "__repr__"); ok {
+        return res
+    }
+
+    if str, ok := obj.(*object.String); ok {
+        return str
+    }
+
+    return &object.String{Value: obj.Inspect()}
+}
+
+// toRepr converts obj to a string the way repr() does, strings are quoted
+// and instances dispatch to __repr__.
+func toRepr(obj object.Object) object.Object {
+    if res, ok := callStringMethod(obj, "__repr__"); ok {
+        return res
+    }
+
+    if str, ok := obj.(*object.String); ok {
+        return &object.String{Value: str.Repr()}
+    }
+
+    return &object.String{Value: obj.Inspect()}
+}
+
+// callStringMethod calls the first of the methods names that obj has, it
+// must return a string.
+func callStringMethod(obj object.Object, names ...string) (object.Object, bool) {
+    for _, name := range names {
+        res, ok := callMethod(obj, name)
+        if !ok {
+            continue
+        }
+
+        if isError(res) {
+            return res, true
+        }
+
+        if _, ok := res.(*object.String); !ok {
+            return newError(
+                object.TypeError,
+                "%s returned non-string (type %s)",
+                name,
+                typeName(res),
+            ), true
+        }
+
+        return res, true
+    }
+
+    return nil, false
+}
+
+// instanceIterator iterates over an instance implementing __next__, until
+// it raises StopIteration.
+type instanceIterator struct {
+    instance *object.Instance
+}
+
+func (ii *instanceIterator) Type() object.ObjectType {
+    return object.ITERATOR_OBJ
+}
+
+func (ii *instanceIterator) Inspect() string {
+    return ii.instance.Inspect()
+}
+
+func (ii *instanceIterator) Iter() object.Iterator {
+    return ii
+}
+
+func (ii *instanceIterator) Next() (object.Object, bool) {
+    res, _ := callMethod(ii.instance, "__next__")
+
+    if err, ok := res.(*object.Error); ok {
+        if err.Class.IsSubclass(object.StopIteration) {
+            return nil, false
+        }
+    }
+
+    return res, true
+}
+
+// getInstanceIterator returns the iterator of an instance from __iter__.
+func getInstanceIterator(
+    instance *object.Instance) (object.Iterator, *object.Error) {
+
+    res, ok := callMethod(instance, "__iter__")
+    if !ok {
+        return nil, newError(
+            object.TypeError,
+            "'%s' object is not iterable",
+            typeName(instance),
+        )
+    }
+
+    switch res := res.(type) {
+    case *object.Error:
+        return nil, res
+    case object.Iterator:
+        return res, nil
+    case *object.Instance:
+        if _, ok := res.Class.Lookup("__next__"); ok {
+            return &instanceIterator{instance: res}, nil
+        }
+    }
+
+    return nil, newError(
+        object.TypeError,
+        "iter() returned non-iterator of type '%s'",
+        typeName(res),
+    )
+}
@@ -2,16 +2,40 @@ package eval
 
 import (
     "fmt"
+	"math"
+	"math/big"
+	"strings"
+
 	"mxshs/pyinterpreter/ast"
 	"mxshs/pyinterpreter/object"
+	"mxshs/pyinterpreter/token"
 )
 
 var (
     TRUE = &object.Boolean{Value: true}
     FALSE = &object.Boolean{Value: false}
     NULL = &object.Null{}
+    BREAK = &object.Break{}
+    CONTINUE = &object.Continue{}
+    NOT_IMPLEMENTED = &object.NotImplemented{}
 )
 
+// handling is the stack of exceptions whose except clauses are currently
+// running, a bare raise re-raises the innermost one.
+var handling []*object.Error
+
+// frames is the call stack of user functions, outermost call first.
+var frames []*object.Frame
+
+// callSite is the position of the statement running in the innermost
+// frame, or of the call of the builtin that is running. User functions
+// that are called implicitly, e.g. the dunder methods of operators or the
+// callbacks of builtins, report it as their call site.
+var callSite token.Position
+
+// maxRecursionDepth is the number of nested calls that raise RecursionError.
+const maxRecursionDepth = 1000
+
 func Eval(node ast.Node, env *object.Env) object.Object {
     switch node := node.(type) {
     case *ast.Program:
@@ -19,9 +43,13 @@ func Eval(node ast.Node, env *object.Env) object.Object {
     case *ast.ExpressionStatement:
         return Eval(node.Expression, env)
     case *ast.IntegerLiteral:
-        return &object.Integer{Value: node.Value}
+        return &object.Integer{Value: node.Value, Big: node.Big}
     case *ast.FloatLiteral:
         return &object.Float{Value: node.Value}
+    case *ast.ImaginaryLiteral:
+        return &object.Complex{Value: complex(0, node.Value)}
+    case *ast.NoneLiteral:
+        return NULL
     case *ast.Boolean:
         if node.Value {
             return TRUE
@@ -30,13 +58,21 @@ func Eval(node ast.Node, env *object.Env) object.Object {
         }
     case *ast.StringLiteral:
         return &object.String{Value: node.Value}
+    case *ast.BytesLiteral:
+        return &object.Bytes{Value: node.Value}
+    case *ast.FormattedString:
+        return evalFormattedString(node, env)
     case *ast.PrefixExpression:
         operand := Eval(node.Right, env)
         if isError(operand) {
             return operand
         } 
-        return evalPrefixExpression(node.Operator, operand)
+        return locate(evalPrefixExpression(node.Operator, operand), node)
     case *ast.InfixExpression:
+        if node.Operator == "and" || node.Operator == "or" {
+            return evalBooleanOperation(node, env)
+        }
+
         left := Eval(node.Left, env)
         if isError(left) {
             return left
@@ -47,11 +83,33 @@ func Eval(node ast.Node, env *object.Env) object.Object {
             return right
         }
 
-        return evalInfixExpression(node.Operator, left, right)
+        return locate(evalInfixExpression(node.Operator, left, right), node)
+    case *ast.ComparisonExpression:
+        return evalComparisonExpression(node, env)
     case *ast.BlockStatement:
         return evalBlockStatement(node.Statements, env)
     case *ast.IfExpression:
         return evalIfExpression(node, env)
+    case *ast.ConditionalExpression:
+        return evalConditionalExpression(node, env)
+    case *ast.WhileStatement:
+        return evalWhileStatement(node, env)
+    case *ast.ForStatement:
+        return evalForStatement(node, env)
+    case *ast.TryStatement:
+        return evalTryStatement(node, env)
+    case *ast.RaiseStatement:
+        return locate(evalRaiseStatement(node, env), node)
+    case *ast.PassStatement:
+        return NULL
+    case *ast.GlobalStatement:
+        // The declarations are resolved by the parser into the scope of
+        // the enclosing function.
+        return NULL
+    case *ast.BreakStatement:
+        return BREAK
+    case *ast.ContinueStatement:
+        return CONTINUE
     case *ast.ReturnStatement:
         val := Eval(node.ReturnValue, env)
         if isError(val) {
@@ -65,36 +123,103 @@ func Eval(node ast.Node, env *object.Env) object.Object {
             return val
         }
 
+        if node.Name == nil {
+            return assignTarget(node.Target, val, env)
+        }
+
         env.Set(node.Name.Value, val)
+    case *ast.AugAssignStatement:
+        return evalAugAssignStatement(node, env)
     case *ast.Name:
-        return evalName(node, env) 
+        return locate(evalName(node, env), node)
     case *ast.FunctionStatement:
+        defaults, err := evalDefaults(node.Arguments, env)
+        if err != nil {
+            return err
+        }
+
         args := node.Arguments
         body := node.Body
-        env.Set(node.Name.Value, &object.Function{Arguments: args, Env: env, Body: body})
+        env.Set(node.Name.Value, &object.Function{
+            Name: node.Name.Value,
+            Arguments: args,
+            Defaults: defaults,
+            Env: env.Closure(),
+            Body: body,
+            Scope: node.Scope,
+        })
+    case *ast.ClassStatement:
+        return evalClassStatement(node, env)
+    case *ast.AttributeExpression:
+        obj := Eval(node.Object, env)
+        if isError(obj) {
+            return obj
+        }
+
+        return locate(getAttribute(obj, node.Name.Value), node)
+    case *ast.LambdaExpression:
+        defaults, err := evalDefaults(node.Arguments, env)
+        if err != nil {
+            return err
+        }
+
+        // The body is wrapped into a return, so calling a lambda is no
+        // different from calling a function.
+        body := &ast.BlockStatement{
+            Token: node.Token,
+            Statements: []ast.Statement{
+                &ast.ReturnStatement{Token: node.Token, ReturnValue: node.Body},
+            },
+        }
+
+        return &object.Function{
+            Name: "<lambda>",
+            Arguments: node.Arguments,
+            Defaults: defaults,
+            Env: env.Closure(),
+            Body: body,
+            Scope: node.Scope,
+        }
     case *ast.CallExpression:
-        // node.Function is literally a name (ident) of a func,
-        // i.e. we address the current env to retrieve the actual function.
-        // It means that anonymous functions are not supported yet.
-        // Lambdas will be added later as a separate type, therefore no
-        // further changes are expected in function call evaluation.
+        // node.Function is any expression, e.g. a name, an attribute or a
+        // lambda, whatever it evaluates to is called.
         function := Eval(node.Function, env)
         if isError(function) {
             return function
         }
 
-        args := evalExpressions(node.Arguments, env)
-        if len(args) == 1 && isError(args[0]) {
-            return args[0]
+        args, kwargs, err := evalCallArguments(node.Arguments, env)
+        if err != nil {
+            return locate(err, node)
         }
         
-        return runFunction(function, args)
+        return locate(runFunction(function, args, kwargs, node.Pos()), node)
     case *ast.ListLiteral:
         elements := []object.Object{}
         for _, elem := range node.Arr {
-            elements = append(elements, Eval(elem, env))
+            evaluated := Eval(elem, env)
+            if isError(evaluated) {
+                return evaluated
+            }
+
+            elements = append(elements, evaluated)
         }
         return &object.List{Arr: elements}
+    case *ast.TupleLiteral:
+        elements := []object.Object{}
+        for _, elem := range node.Elements {
+            evaluated := Eval(elem, env)
+            if isError(evaluated) {
+                return evaluated
+            }
+
+            elements = append(elements, evaluated)
+        }
+        return &object.Tuple{Elements: elements}
+    case *ast.DictLiteral:
+        return evalDictLiteral(node, env)
+    case *ast.SliceExpression:
+        return evalSliceExpression(node, env)
     case *ast.IndexExpression:
         Struct := Eval(node.Struct, env)
         if isError(Struct) {
@@ -106,7 +231,7 @@ func Eval(node ast.Node, env *object.Env) object.Object {
             return idx
         }
         
-        return evalIndexExpression(Struct, idx)
+        return locate(evalIndexExpression(Struct, idx), node)
     }
 
     return NULL
@@ -116,6 +241,7 @@ func evalProgram(statements []ast.Statement, env *object.Env) object.Object {
     var res object.Object
 
     for _, statement := range statements {
+        callSite = statement.Pos()
         res = Eval(statement, env)
 
         switch res := res.(type) {
@@ -135,13 +261,11 @@ func evalBlockStatement(
     var res object.Object
 
     for _, statement := range statements {
+        callSite = statement.Pos()
         res = Eval(statement, env)
 
-        if res != nil {
-            if (res.Type() == object.
-                RETURN_VALUE || res.Type() == object.ERROR_OBJ) {
-                return res        
-            }
+        if isSignal(res) {
+            return res
         }
     }
 
@@ -150,31 +274,104 @@ func evalBlockStatement(
 
 func evalPrefixExpression(op string, operand object.Object) object.Object {
     switch op {
-    case "!":
+    case "!", "not":
         return evalBangOperatorExpression(operand)
     case "-":
         return evalMinusPrefixOperatorExpression(operand)
+    case "~":
+        return evalInvertPrefixOperatorExpression(operand)
     default:
-        return newError("unknown operator %s for type %s", op, operand.Type())
+        return newError(
+            object.TypeError,
+            "unknown operator %s for type %s",
+            op,
+            operand.Type(),
+        )
     }
 }
 
 func evalBangOperatorExpression(operand object.Object) object.Object {
-    switch operand {
-    case TRUE:
-        return FALSE
-    case FALSE:
-        return TRUE
-    case NULL:
-        return FALSE
-    default:
-        return FALSE
+    truth, err := checkCondition(operand)
+    if err != nil {
+        return err
+    }
+
+    return nativeBoolToBoolean(!truth)
+}
+
+// evalComparisonExpression evaluates a chain of comparisons from left to
+// right, it stops at the first one that doesn't hold and returns its
+// result.
+func evalComparisonExpression(
+    ce *ast.ComparisonExpression, env *object.Env) object.Object {
+
+    left := Eval(ce.Operands[0], env)
+    if isError(left) {
+        return left
+    }
+
+    var res object.Object
+
+    for i, op := range ce.Operators {
+        right := Eval(ce.Operands[i + 1], env)
+        if isError(right) {
+            return right
+        }
+
+        res = locate(evalInfixExpression(op, left, right), ce)
+        if isError(res) {
+            return res
+        }
+
+        truth, err := checkCondition(res)
+        if err != nil {
+            return locate(err, ce)
+        }
+
+        if !truth {
+            return res
+        }
+
+        left = right
+    }
+
+    return res
+}
+
+// evalBooleanOperation evaluates "and" and "or". The right operand is only
+// evaluated if the left one doesn't decide the result, which is the last
+// operand evaluated rather than a boolean.
+func evalBooleanOperation(ie *ast.InfixExpression, env *object.Env) object.Object {
+    left := Eval(ie.Left, env)
+    if isError(left) {
+        return left
+    }
+
+    truth, err := checkCondition(left)
+    if err != nil {
+        return locate(err, ie.Left)
+    }
+
+    if truth == (ie.Operator == "or") {
+        return left
     }
+
+    return Eval(ie.Right, env)
 }
 
 func evalMinusPrefixOperatorExpression(operand object.Object) object.Object {
+    if res, ok := callMethod(operand, "__neg__"); ok {
+        return res
+    }
+
+    operand = boolToInteger(operand)
+
+    if complexVal, ok := operand.(*object.Complex); ok {
+        return &object.Complex{Value: -complexVal.Value}
+    }
+
     if !IsNumeric(operand){
-        return newError("unknown operator - for type %s",
+        return newError(object.TypeError, "unknown operator - for type %s",
             operand.Type(),
         )
     }
@@ -183,50 +380,114 @@ func evalMinusPrefixOperatorExpression(operand object.Object) object.Object {
         return &object.Float{Value: -operand.(*object.Float).Value}
     }
 
-    return &object.Integer{Value: -operand.(*object.Integer).Value}
+    integer := operand.(*object.Integer)
+    if integer.Big != nil || integer.Value == math.MinInt64 {
+        return object.NewBigInteger(new(big.Int).Neg(integer.BigValue()))
+    }
+
+    return &object.Integer{Value: -integer.Value}
+}
+
+func evalInvertPrefixOperatorExpression(operand object.Object) object.Object {
+    if res, ok := callMethod(operand, "__invert__"); ok {
+        return res
+    }
+
+    integer, ok := boolToInteger(operand).(*object.Integer)
+    if !ok {
+        return newError(
+            object.TypeError,
+            "bad operand type for unary ~: '%s'",
+            typeName(operand),
+        )
+    }
+
+    if integer.Big != nil {
+        return object.NewBigInteger(new(big.Int).Not(integer.Big))
+    }
+
+    return &object.Integer{Value: ^integer.Value}
 }
 
 func evalInfixExpression(
     op string, left, right object.Object) object.Object {
+    switch op {
+    case "is":
+        return nativeBoolToBoolean(left == right)
+    case "is not":
+        return nativeBoolToBoolean(left != right)
+    case "in":
+        return evalMembership(left, right)
+    case "not in":
+        res := evalMembership(left, right)
+        if isError(res) {
+            return res
+        }
+
+        return nativeBoolToBoolean(res == FALSE)
+    }
+
     switch {
+    case isInstance(left) || isInstance(right):
+        return evalInstanceInfixExpression(op, left, right)
+    case left.Type() == object.BOOL_OBJ && right.Type() == object.BOOL_OBJ &&
+        (op == "&" || op == "|" || op == "^"):
+        res := evalIntegerInfixExpression(op, boolToInteger(left), boolToInteger(right))
+        return nativeBoolToBoolean(res.(*object.Integer).Value != 0)
+    case (left.Type() == object.BOOL_OBJ || right.Type() == object.BOOL_OBJ) &&
+        isNumber(left) && isNumber(right):
+        // Booleans are the ints 0 and 1 in arithmetic and comparisons.
+        return evalInfixExpression(op, boolToInteger(left), boolToInteger(right))
+    case (isComplex(left) || isComplex(right)) &&
+        (IsNumeric(left) || isComplex(left)) && (IsNumeric(right) || isComplex(right)):
+        return evalComplexInfixExpression(op, left, right)
     case IsNumeric(left) && IsNumeric(right):
         if left.Type() == object.FLOAT_OBJ {
             if right.Type() == object.FLOAT_OBJ {
                 return evalFloatInfixExpression(op, left, right)
             }
 
-            return evalFloatInfixExpression(
-                op,
-                left,
-                &object.Float{
-                    Value: float64(right.(*object.Integer).Value),
-                },
-            )
+            rightVal, err := intToFloat(op, right.(*object.Integer))
+            if err != nil {
+                return err
+            }
+
+            return evalFloatInfixExpression(op, left, rightVal)
         } else {
             if right.Type() == object.INTEGER_OBJ {
                 return evalIntegerInfixExpression(op, left, right)
             }
 
-            return evalFloatInfixExpression(
-                op,
-                &object.Float{
-                    Value: float64(left.(*object.Integer).Value),
-                },
-                right,
-            )
+            leftVal, err := intToFloat(op, left.(*object.Integer))
+            if err != nil {
+                return err
+            }
+
+            return evalFloatInfixExpression(op, leftVal, right)
         }
     case left.Type() == object.BOOL_OBJ || right.Type() == object.BOOL_OBJ:
         return evalBoolInfixExpression(op, left, right)
     case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
         return evalStringInfixExpression(op, left, right)
+    case left.Type() == object.BYTES_OBJ && right.Type() == object.BYTES_OBJ:
+        return evalBytesInfixExpression(op, left, right)
+    case isSequence(left) && left.Type() == right.Type():
+        return evalSequenceInfixExpression(op, left, right)
+    case left.Type() == object.DICT_OBJ && right.Type() == object.DICT_OBJ:
+        return evalDictInfixExpression(op, left.(*object.Dict), right.(*object.Dict))
+    case op == "==" || op == "!=":
+        // Any other objects are only equal to themselves.
+        return nativeBoolToBoolean((left == right) == (op == "=="))
     case left.Type() != right.Type():
-        return newError("type mismatch in %s %s %s",
+        return newError(object.TypeError, "type mismatch in %s %s %s",
             op,
             left.Type(),
             right.Type(),
         )
     default:
-        return newError("unknown operator %s for types %s and %s", 
+        return newError(
+            object.TypeError,
+            "unknown operator %s for types %s and %s",
             op,
             left.Type(),
             right.Type(),
@@ -234,30 +495,99 @@ func evalInfixExpression(
     }
 }
 
+// evalIntegerInfixExpression applies op to two ints with int64 arithmetic,
+// unless an operand is a big int or the result overflows.
 func evalIntegerInfixExpression(
     op string, left, right object.Object) object.Object {
-    leftVal := left.(*object.Integer).Value
-    rightVal := right.(*object.Integer).Value
+    leftInt, rightInt := left.(*object.Integer), right.(*object.Integer)
+    if leftInt.Big != nil || rightInt.Big != nil {
+        return evalBigIntegerInfixExpression(
+            op,
+            leftInt.BigValue(),
+            rightInt.BigValue(),
+        )
+    }
+
+    leftVal := leftInt.Value
+    rightVal := rightInt.Value
 
     switch op {
         case "+":
+            if addOverflows(leftVal, rightVal) {
+                break
+            }
+
             return &object.Integer{Value: leftVal + rightVal}
         case "-": 
+            if subOverflows(leftVal, rightVal) {
+                break
+            }
+
             return &object.Integer{Value: leftVal - rightVal}
         case "*":
+            if mulOverflows(leftVal, rightVal) {
+                break
+            }
+
             return &object.Integer{Value: leftVal * rightVal}
         case "/":
-            return &object.Integer{Value: leftVal / rightVal}
+            if rightVal == 0 {
+                return newError(object.ZeroDivisionError, "division by zero")
+            }
+
+            return &object.Float{Value: float64(leftVal) / float64(rightVal)}
+        case "//":
+            if rightVal == 0 {
+                return newError(
+                    object.ZeroDivisionError,
+                    "integer division or modulo by zero",
+                )
+            }
+
+            if leftVal == math.MinInt64 && rightVal == -1 {
+                break
+            }
+
+            return &object.Integer{Value: floorDiv(leftVal, rightVal)}
+        case "%":
+            if rightVal == 0 {
+                return newError(object.ZeroDivisionError, "integer modulo by zero")
+            }
+
+            return &object.Integer{Value: floorMod(leftVal, rightVal)}
         case "**":
             if rightVal < 0 {
-                return &object.Float{
-                    Value: powFloat(
-                        float64(leftVal),
-                        float64(rightVal),
-                    ),
+                return evalFloatInfixExpression(
+                    op,
+                    &object.Float{Value: float64(leftVal)},
+                    &object.Float{Value: float64(rightVal)},
+                )
+            }
+            if res, ok := pow(leftVal, rightVal); ok {
+                return &object.Integer{Value: res}
+            }
+        case "&":
+            return &object.Integer{Value: leftVal & rightVal}
+        case "|":
+            return &object.Integer{Value: leftVal | rightVal}
+        case "^":
+            return &object.Integer{Value: leftVal ^ rightVal}
+        case "<<", ">>":
+            if rightVal < 0 {
+                return newError(object.ValueError, "negative shift count")
+            }
+
+            if op == "<<" {
+                if rightVal >= 63 || (leftVal << rightVal) >> rightVal != leftVal {
+                    break
                 }
+
+                return &object.Integer{Value: leftVal << rightVal}
             }
-            return &object.Integer{Value: pow(leftVal, rightVal)}
+
+            // Shifting a negative number right rounds towards negative
+            // infinity, like a floor division by a power of two.
+            return &object.Integer{Value: leftVal >> rightVal}
         case "<":
             if leftVal < rightVal {
                 return TRUE
@@ -295,12 +625,21 @@ func evalIntegerInfixExpression(
                 return TRUE
             }
         default:
-            return newError("unknown operator %s for types %s and %s",
+            return newError(
+                object.TypeError,
+                "unknown operator %s for types %s and %s",
                 op,
                 left.Type(),
                 right.Type(),
             )
         }
+
+    // The result overflowed int64.
+    return evalBigIntegerInfixExpression(
+        op,
+        leftInt.BigValue(),
+        rightInt.BigValue(),
+    )
 }
 
 func evalFloatInfixExpression(
@@ -316,9 +655,30 @@ func evalFloatInfixExpression(
         case "*":
             return &object.Float{Value: leftVal * rightVal}
         case "/":
+            if rightVal == 0 {
+                return newError(object.ZeroDivisionError, "float division by zero")
+            }
+
             return &object.Float{Value: leftVal / rightVal}
+        case "//":
+            if rightVal == 0 {
+                return newError(
+                    object.ZeroDivisionError,
+                    "float floor division by zero",
+                )
+            }
+
+            div, _ := floatDivmod(leftVal, rightVal)
+            return &object.Float{Value: div}
+        case "%":
+            if rightVal == 0 {
+                return newError(object.ZeroDivisionError, "float modulo by zero")
+            }
+
+            _, mod := floatDivmod(leftVal, rightVal)
+            return &object.Float{Value: mod}
         case "**":
-            return &object.Float{Value: powFloat(leftVal, rightVal)} 
+            return floatPow(leftVal, rightVal)
         case "<":
             if leftVal < rightVal {
                 return TRUE
@@ -356,7 +716,9 @@ func evalFloatInfixExpression(
                 return TRUE
             }
         default:
-            return newError("unknown operator %s for types %s and %s",
+            return newError(
+                object.TypeError,
+                "unknown operator %s for types %s and %s",
                 op,
                 left.Type(),
                 right.Type(),
@@ -386,12 +748,28 @@ func evalBoolInfixExpression(
 
 func evalStringInfixExpression(op string,
     left, right object.Object) object.Object {
+    leftVal := left.(*object.String).Value
+    rightVal := right.(*object.String).Value
+
     switch op {
     case "+":
-        return &object.String{
-            Value: left.(*object.String).Value + right.(*object.String).Value}
+        return &object.String{Value: leftVal + rightVal}
+    case "==":
+        return nativeBoolToBoolean(leftVal == rightVal)
+    case "!=":
+        return nativeBoolToBoolean(leftVal != rightVal)
+    case "<":
+        return nativeBoolToBoolean(leftVal < rightVal)
+    case ">":
+        return nativeBoolToBoolean(leftVal > rightVal)
+    case "<=":
+        return nativeBoolToBoolean(leftVal <= rightVal)
+    case ">=":
+        return nativeBoolToBoolean(leftVal >= rightVal)
     default:
-        return newError("unknown operator %s for types %s and %s",
+        return newError(
+            object.TypeError,
+            "unknown operator %s for types %s and %s",
             op,
             left.Type(),
             right.Type(),
@@ -399,33 +777,394 @@ func evalStringInfixExpression(op string,
     }
 }
 
+// evalBytesInfixExpression concatenates and compares bytes like strings.
+func evalBytesInfixExpression(
+    op string, left, right object.Object) object.Object {
+
+    switch op {
+    case "+", "==", "!=", "<", ">", "<=", ">=":
+    default:
+        return newError(
+            object.TypeError,
+            "unsupported operand type(s) for %s: 'bytes' and 'bytes'",
+            op,
+        )
+    }
+
+    res := evalStringInfixExpression(
+        op,
+        &object.String{Value: left.(*object.Bytes).Value},
+        &object.String{Value: right.(*object.Bytes).Value},
+    )
+
+    if str, ok := res.(*object.String); ok {
+        return &object.Bytes{Value: str.Value}
+    }
+
+    return res
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Env) object.Object {
     condition := Eval(ie.Condition, env)
     if isError(condition) {
         return condition
     }
 
-    if checkCondition(condition) {
+    truth, err := checkCondition(condition)
+    if err != nil {
+        return locate(err, ie.Condition)
+    }
+
+    if truth {
         return Eval(ie.Consequence, env)
-    } else if ie.Alternative != nil {
+    }
+
+    for _, elif := range ie.Elifs {
+        condition := Eval(elif.Condition, env)
+        if isError(condition) {
+            return condition
+        }
+
+        truth, err := checkCondition(condition)
+        if err != nil {
+            return locate(err, elif.Condition)
+        }
+
+        if truth {
+            return Eval(elif.Consequence, env)
+        }
+    }
+
+    if ie.Alternative != nil {
         return Eval(ie.Alternative, env)
     } else {
         return NULL
     }
 }
 
+func evalConditionalExpression(
+    ce *ast.ConditionalExpression, env *object.Env) object.Object {
+
+    condition := Eval(ce.Condition, env)
+    if isError(condition) {
+        return condition
+    }
+
+    truth, err := checkCondition(condition)
+    if err != nil {
+        return locate(err, ce.Condition)
+    }
+
+    if truth {
+        return Eval(ce.Consequence, env)
+    }
+
+    return Eval(ce.Alternative, env)
+}
+
+func evalWhileStatement(
+    ws *ast.WhileStatement, env *object.Env) object.Object {
+
+    for {
+        condition := Eval(ws.Condition, env)
+        if isError(condition) {
+            return condition
+        }
+
+        truth, err := checkCondition(condition)
+        if err != nil {
+            return locate(err, ws.Condition)
+        }
+
+        if !truth {
+            break
+        }
+
+        res := Eval(ws.Body, env)
+        if res != nil {
+            switch res.Type() {
+            case object.BREAK_SIGNAL:
+                return NULL
+            case object.RETURN_VALUE, object.ERROR_OBJ:
+                return res
+            }
+        }
+    }
+
+    if ws.Alternative != nil {
+        return Eval(ws.Alternative, env)
+    }
+
+    return NULL
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Env) object.Object {
+    iterable := Eval(fs.Iterable, env)
+    if isError(iterable) {
+        return iterable
+    }
+
+    iterator, err := getIterator(iterable)
+    if err != nil {
+        return locate(err, fs.Iterable)
+    }
+
+    for {
+        item, ok := iterator.Next()
+        if !ok {
+            break
+        }
+
+        if isError(item) {
+            return item
+        }
+
+        if res := assignTarget(fs.Target, item, env); isError(res) {
+            return res
+        }
+
+        res := Eval(fs.Body, env)
+        if res != nil {
+            switch res.Type() {
+            case object.BREAK_SIGNAL:
+                return NULL
+            case object.RETURN_VALUE, object.ERROR_OBJ:
+                return res
+            }
+        }
+    }
+
+    if fs.Alternative != nil {
+        return Eval(fs.Alternative, env)
+    }
+
+    return NULL
+}
+
+func evalTryStatement(ts *ast.TryStatement, env *object.Env) object.Object {
+    res := Eval(ts.Body, env)
+
+    if err, ok := res.(*object.Error); ok {
+        res = evalExceptClauses(ts.Handlers, err, env)
+    } else if ts.Alternative != nil && !isSignal(res) {
+        res = Eval(ts.Alternative, env)
+    }
+
+    if ts.Finally != nil {
+        // Leaving the finally block early discards the pending result,
+        // including an exception that was not handled.
+        if finally := Eval(ts.Finally, env); isSignal(finally) {
+            return finally
+        }
+    }
+
+    return res
+}
+
+// evalExceptClauses runs the first handler matching err and returns its
+// result, or err itself when no handler matches.
+func evalExceptClauses(
+    handlers []*ast.ExceptClause,
+    err *object.Error,
+    env *object.Env) object.Object {
+
+    for _, handler := range handlers {
+        if handler.Class != nil {
+            class := Eval(handler.Class, env)
+            if isError(class) {
+                return class
+            }
+
+            // A tuple of classes catches the exceptions of any of them.
+            classes := []object.Object{class}
+            if tuple, ok := class.(*object.Tuple); ok {
+                classes = tuple.Elements
+            }
+
+            matches := false
+            for _, class := range classes {
+                exceptionClass, ok := class.(*object.ExceptionClass)
+                if !ok {
+                    typeErr := newError(
+                        object.TypeError,
+                        "catching classes that do not inherit from " +
+                            "BaseException is not allowed",
+                    )
+
+                    return locate(typeErr, handler.Class)
+                }
+
+                if err.Class.IsSubclass(exceptionClass) {
+                    matches = true
+                }
+            }
+
+            if !matches {
+                continue
+            }
+        }
+
+        if handler.Name != nil {
+            env.Set(handler.Name.Value, err.Exception())
+        }
+
+        handling = append(handling, err)
+        res := Eval(handler.Body, env)
+        handling = handling[:len(handling) - 1]
+
+        return res
+    }
+
+    return err
+}
+
+func evalRaiseStatement(
+    rs *ast.RaiseStatement, env *object.Env) object.Object {
+
+    if rs.Value == nil {
+        if len(handling) == 0 {
+            return newError(
+                object.RuntimeError,
+                "No active exception to reraise",
+            )
+        }
+
+        return handling[len(handling) - 1]
+    }
+
+    val := Eval(rs.Value, env)
+    if isError(val) {
+        return val
+    }
+
+    // Raising a class raises a new instance of it.
+    if class, ok := val.(*object.ExceptionClass); ok {
+        val = newExceptionInstance(class, nil, nil, rs.Token.Pos)
+        if isError(val) {
+            return val
+        }
+    }
+
+    switch val := val.(type) {
+    case *object.ExceptionInstance:
+        return &object.Error{Class: val.Class, Message: val.Message, Value: val}
+    default:
+        return newError(
+            object.TypeError,
+            "exceptions must derive from BaseException",
+        )
+    }
+}
+
+// newExceptionInstance creates an instance of an exception class, its
+// message is made of the arguments unless the __init__ of a user defined
+// subclass says otherwise.
+func newExceptionInstance(
+    class *object.ExceptionClass,
+    args []object.Object,
+    kwargs *object.Dict,
+    callPos token.Position) object.Object {
+
+    exception := object.NewExceptionInstance(class, exceptionMessage(args))
+
+    init, ok := class.Lookup("__init__")
+    if !ok {
+        if kwargs != nil {
+            return newError(
+                object.TypeError,
+                "%s() takes no keyword arguments",
+                class.Name,
+            )
+        }
+
+        return exception
+    }
+
+    res := runFunction(bindMethod(init, exception), args, kwargs, callPos)
+    if isError(res) {
+        return res
+    }
+
+    if res != NULL {
+        return newError(
+            object.TypeError,
+            "__init__() should return None, not '%s'",
+            res.Type(),
+        )
+    }
+
+    return exception
+}
+
+// exceptionMessage is the message of an exception created with args.
+func exceptionMessage(args []object.Object) string {
+    msgs := []string{}
+    for _, arg := range args {
+        msgs = append(msgs, arg.Inspect())
+    }
+
+    switch len(msgs) {
+    case 0:
+        return ""
+    case 1:
+        return msgs[0]
+    default:
+        return "(" + strings.Join(msgs, ", ") + ")"
+    }
+}
+
+// exceptionInit is BaseException.__init__ bound to exception, it resets
+// the message to one made of its arguments.
+func exceptionInit(exception *object.ExceptionInstance) *object.Bltin {
+    return &object.Bltin{
+        Name: "__init__",
+        Fn: func(args ...object.Object) object.Object {
+            exception.Message = exceptionMessage(args)
+
+            return NULL
+        },
+    }
+}
+
+// getIterator returns an iterator over the items of obj, which has to
+// implement the object.Iterable protocol.
+func getIterator(obj object.Object) (object.Iterator, *object.Error) {
+    if instance, ok := obj.(*object.Instance); ok {
+        return getInstanceIterator(instance)
+    }
+
+    iterable, ok := obj.(object.Iterable)
+    if !ok {
+        return nil, newError(
+            object.TypeError,
+            "'%s' object is not iterable",
+            obj.Type(),
+        )
+    }
+
+    return iterable.Iter(), nil
+}
+
 func evalName(name *ast.Name, env *object.Env) object.Object {
     val, ok := env.Get(name.Value)
     if ok {
         return val
     }
 
+    if env.IsLocal(name.Value) {
+        return newError(
+            object.UnboundLocalError,
+            "cannot access local variable '%s' where it is not associated with a value",
+            name.Value,
+        )
+    }
+
     val, ok = bltins[name.Value]
     if ok {
         return val
     }
 
-    return newError("name is not declared: %s", name.Value)
+    return newError(object.NameError, "name is not declared: %s", name.Value)
 }
 
 func evalExpressions(
@@ -445,24 +1184,85 @@ func evalExpressions(
     return res
 }
 
+// runFunction calls function with args and kwargs (nil without keyword
+// arguments), callPos is the position of the call recorded in the
+// traceback of errors raised by the call.
 func runFunction(
-    function object.Object, args []object.Object) object.Object {
+    function object.Object,
+    args []object.Object,
+    kwargs *object.Dict,
+    callPos token.Position) object.Object {
 
     switch function := function.(type) {
     case *object.Bltin:
+        outer := callSite
+        callSite = callPos
+        defer func() { callSite = outer }()
+
+        if function.KwFn != nil {
+            return function.KwFn(args, kwargs)
+        }
+
+        if kwargs != nil {
+            return newError(
+                object.TypeError,
+                "%s() takes no keyword arguments",
+                function.Name,
+            )
+        }
+
         return function.Fn(args...)
+    case *object.ExceptionClass:
+        return newExceptionInstance(function, args, kwargs, callPos)
+    case *object.Class:
+        return newInstance(function, args, kwargs, callPos)
+    case *object.BoundMethod:
+        args = append([]object.Object{function.Self}, args...)
+
+        return runFunction(function.Function, args, kwargs, callPos)
+    case *object.Instance:
+        if call, ok := function.Class.Lookup("__call__"); ok {
+            return runFunction(bindMethod(call, function), args, kwargs, callPos)
+        }
+
+        return newError(
+            object.TypeError,
+            "'%s' object is not callable",
+            function.Class.Name,
+        )
     case *object.Function:
-        fnEnv := object.NewNestedEnv(function.Env)
+        if len(frames) >= maxRecursionDepth {
+            return newError(
+                object.RecursionError,
+                "maximum recursion depth exceeded",
+            )
+        }
+
+        fnEnv := object.NewFunctionEnv(function.Env, function.Scope)
 
-        for i, arg := range function.Arguments {
-            fnEnv.Set(arg.Value, args[i])
+        if err := bindArguments(function, args, kwargs, fnEnv); err != nil {
+            return err
         }
 
+        outer := callSite
+        defer func() { callSite = outer }()
+
+        frames = append(frames, &object.Frame{
+            Name: function.Name,
+            CallPos: callPos,
+            Env: fnEnv,
+            Function: function,
+        })
         evaluated := Eval(function.Body, fnEnv)
+        frames = frames[:len(frames) - 1]
 
         return convertFunctionReturn(evaluated)    
     default:
-        return newError("expected type Function, got: %s", function.Type())
+        return newError(
+            object.TypeError,
+            "expected type Function, got: %s",
+            function.Type(),
+        )
     }
 }
 
@@ -476,10 +1276,24 @@ func convertFunctionReturn(res object.Object) object.Object {
 
 func evalIndexExpression(Struct, index object.Object) object.Object {
     switch {
-    case Struct.Type() == object.LIST && index.Type() == object.INTEGER_OBJ:
-        return evalListIndexExpression(Struct, index)
+    case isSequence(Struct) || Struct.Type() == object.STRING_OBJ ||
+        Struct.Type() == object.BYTES_OBJ:
+        return evalSequenceIndexExpression(Struct, index)
+    case Struct.Type() == object.DICT_OBJ:
+        return evalDictIndexExpression(Struct, index)
+    case isInstance(Struct):
+        if res, ok := callMethod(Struct, "__getitem__", index); ok {
+            return res
+        }
+
+        return newError(
+            object.TypeError,
+            "'%s' object is not subscriptable",
+            typeName(Struct),
+        )
     default:
         return newError(
+            object.TypeError,
             "attempting to apply unsupported index: %s[%s]",
             Struct.Type(),
             index.Type(),
@@ -487,32 +1301,378 @@ func evalIndexExpression(Struct, index object.Object) object.Object {
     }
 }
 
-func evalListIndexExpression(list, index object.Object) object.Object {
-    arr := list.(*object.List).Arr
-    idx := index.(*object.Integer).Value
+func evalDictIndexExpression(dict, index object.Object) object.Object {
+    key, err := toHashable(index)
+    if err != nil {
+        return err
+    }
 
-    if idx < 0 || idx > int64(len(arr) - 1) {
-        return NULL
+    val, ok := dict.(*object.Dict).Get(key)
+    if !ok {
+        return newError(object.KeyError, "%s", index.Inspect())
+    }
+
+    return val
+}
+
+func evalDictLiteral(dl *ast.DictLiteral, env *object.Env) object.Object {
+    dict := object.NewDict()
+
+    for i, keyNode := range dl.Keys {
+        key := Eval(keyNode, env)
+        if isError(key) {
+            return key
+        }
+
+        hashable, err := toHashable(key)
+        if err != nil {
+            return locate(err, keyNode)
+        }
+
+        val := Eval(dl.Values[i], env)
+        if isError(val) {
+            return val
+        }
+
+        dict.Set(hashable, val)
+    }
+
+    return dict
+}
+
+// evalDictInfixExpression applies op to two dicts, which are equal when
+// they have the same keys mapped to equal values.
+func evalDictInfixExpression(op string, left, right *object.Dict) object.Object {
+    if op != "==" && op != "!=" {
+        return newError(
+            object.TypeError,
+            "unsupported operand type(s) for %s: '%s' and '%s'",
+            op,
+            left.Type(),
+            right.Type(),
+        )
+    }
+
+    if left.Len() != right.Len() {
+        return nativeBoolToBoolean(op == "!=")
     }
 
-    return arr[idx]
+    for _, pair := range left.Pairs {
+        val, ok := right.Get(pair.Key.(object.Hashable))
+        if !ok {
+            return nativeBoolToBoolean(op == "!=")
+        }
+
+        equal := evalInfixExpression("==", pair.Value, val)
+        if isError(equal) {
+            return equal
+        }
+
+        truth, err := checkCondition(equal)
+        if err != nil {
+            return err
+        }
+
+        if !truth {
+            return nativeBoolToBoolean(op == "!=")
+        }
+    }
+
+    return nativeBoolToBoolean(op == "==")
+}
+
+func init() {
+    object.KeysEqual = keysEqual
 }
 
-func checkCondition(obj object.Object) bool {
+// keysEqual is == for dictionary keys.
+func keysEqual(a, b object.Object) bool {
+    return evalInfixExpression("==", a, b) == TRUE
+}
+
+// isNumber reports whether obj is a bool, an int, a float or a complex
+// number.
+func isNumber(obj object.Object) bool {
+    return obj.Type() == object.BOOL_OBJ || IsNumeric(obj) || isComplex(obj)
+}
+
+func boolToInteger(obj object.Object) object.Object {
     switch obj {
-        case TRUE:
-            return true
-        case FALSE:
-            return false
-        case NULL:
-            return false
+    case TRUE:
+        return &object.Integer{Value: 1}
+    case FALSE:
+        return &object.Integer{Value: 0}
+    default:
+        return obj
+    }
+}
+
+// toHashable returns obj as a dictionary key. Tuples are only hashable if
+// all of their elements are.
+func toHashable(obj object.Object) (object.Hashable, *object.Error) {
+    hashable, ok := obj.(object.Hashable)
+    if !ok {
+        return nil, newError(
+            object.TypeError,
+            "unhashable type: '%s'",
+            typeName(obj),
+        )
+    }
+
+    if tuple, ok := obj.(*object.Tuple); ok {
+        for _, elem := range tuple.Elements {
+            if _, err := toHashable(elem); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    return hashable, nil
+}
+
+// assignTarget binds val to the target of an assignment or of a for loop,
+// tuple and list targets unpack it.
+func assignTarget(
+    target ast.Expression, val object.Object, env *object.Env) object.Object {
+
+    switch target := target.(type) {
+    case *ast.Name:
+        env.Set(target.Value, val)
+
+        return NULL
+    case *ast.TupleLiteral:
+        return locate(unpackTargets(target.Elements, val, env), target)
+    case *ast.ListLiteral:
+        return locate(unpackTargets(target.Arr, val, env), target)
+    case *ast.IndexExpression:
+        Struct := Eval(target.Struct, env)
+        if isError(Struct) {
+            return Struct
+        }
+
+        idx := Eval(target.Value, env)
+        if isError(idx) {
+            return idx
+        }
+
+        return locate(evalSetIndexExpression(Struct, idx, val), target)
+    case *ast.AttributeExpression:
+        obj := Eval(target.Object, env)
+        if isError(obj) {
+            return obj
+        }
+
+        return locate(setAttribute(obj, target.Name.Value, val), target)
+    default:
+        return newError(
+            object.TypeError,
+            "cannot assign to %s",
+            target.String(),
+        )
+    }
+}
+
+// evalAugAssignStatement evaluates the target of an augmented assignment
+// only once, e.g. the list and the index of "xs[i] += 1", and stores the
+// result back into it.
+func evalAugAssignStatement(
+    as *ast.AugAssignStatement, env *object.Env) object.Object {
+
+    switch target := as.Target.(type) {
+    case *ast.Name:
+        current := Eval(target, env)
+        if isError(current) {
+            return current
+        }
+
+        res := evalAugmentedValue(as, current, env)
+        if isError(res) {
+            return res
+        }
+
+        env.Set(target.Value, res)
+    case *ast.IndexExpression:
+        Struct := Eval(target.Struct, env)
+        if isError(Struct) {
+            return Struct
+        }
+
+        idx := Eval(target.Value, env)
+        if isError(idx) {
+            return idx
+        }
+
+        current := locate(evalIndexExpression(Struct, idx), target)
+        if isError(current) {
+            return current
+        }
+
+        res := evalAugmentedValue(as, current, env)
+        if isError(res) {
+            return res
+        }
+
+        return locate(evalSetIndexExpression(Struct, idx, res), target)
+    case *ast.AttributeExpression:
+        obj := Eval(target.Object, env)
+        if isError(obj) {
+            return obj
+        }
+
+        current := locate(getAttribute(obj, target.Name.Value), target)
+        if isError(current) {
+            return current
+        }
+
+        res := evalAugmentedValue(as, current, env)
+        if isError(res) {
+            return res
+        }
+
+        return locate(setAttribute(obj, target.Name.Value, res), target)
+    }
+
+    return NULL
+}
+
+// evalAugmentedValue evaluates the right hand side of an augmented
+// assignment and applies its operator to the current value of the target.
+func evalAugmentedValue(
+    as *ast.AugAssignStatement,
+    current object.Object,
+    env *object.Env) object.Object {
+
+    val := Eval(as.Value, env)
+    if isError(val) {
+        return val
+    }
+
+    return locate(evalInplaceExpression(as.Operator, current, val), as)
+}
+
+func evalSetIndexExpression(Struct, index, val object.Object) object.Object {
+    switch Struct := Struct.(type) {
+    case *object.List:
+        switch index := boolToInteger(index).(type) {
+        case *object.Integer:
+            i, err := indexValue(index)
+            if err != nil {
+                return err
+            }
+
+            i, ok := normalizeIndex(i, int64(len(Struct.Arr)))
+            if !ok {
+                return newError(
+                    object.IndexError,
+                    "list assignment index out of range",
+                )
+            }
+
+            Struct.Arr[i] = val
+
+            return NULL
+        case *object.Slice:
+            return assignSlice(Struct, index, val)
         default:
-            return true
+            return newError(
+                object.TypeError,
+                "list indices must be integers or slices, not %s",
+                typeName(index),
+            )
+        }
+    case *object.Dict:
+        key, err := toHashable(index)
+        if err != nil {
+            return err
+        }
+
+        Struct.Set(key, val)
+
+        return NULL
+    default:
+        if res, ok := callMethod(Struct, "__setitem__", index, val); ok {
+            if isError(res) {
+                return res
+            }
+
+            return NULL
+        }
+
+        return newError(
+            object.TypeError,
+            "'%s' object does not support item assignment",
+            typeName(Struct),
+        )
     }
 }
 
-func newError(fmtString string, args ...interface{}) *object.Error {
-    return &object.Error{Message: fmt.Sprintf(fmtString, args...)}
+func nativeBoolToBoolean(value bool) *object.Boolean {
+    if value {
+        return TRUE
+    }
+
+    return FALSE
+}
+
+// checkCondition is the truth test of conditions and boolean operators:
+// None, False, zero, empty sizeable objects and instances that __bool__ or
+// __len__ report as false are false, anything else is true.
+func checkCondition(obj object.Object) (bool, *object.Error) {
+    switch obj := obj.(type) {
+    case *object.Boolean:
+        return obj.Value, nil
+    case *object.Null:
+        return false, nil
+    case *object.Integer:
+        return obj.Value != 0 || obj.Big != nil, nil
+    case *object.Float:
+        return obj.Value != 0, nil
+    case *object.Complex:
+        return obj.Value != 0, nil
+    case *object.Instance:
+        return instanceTruth(obj)
+    case object.Sized:
+        return obj.Len() != 0, nil
+    default:
+        return true, nil
+    }
+}
+
+func newError(
+    class *object.ExceptionClass,
+    fmtString string,
+    args ...interface{}) *object.Error {
+
+    return &object.Error{Class: class, Message: fmt.Sprintf(fmtString, args...)}
+}
+
+// locate stamps the position of node onto an error that doesn't carry a
+// position yet, so the innermost failing node is the one reported. The
+// error's traceback is captured at the same time, while the call stack
+// still is the one the error was raised in.
+func locate(obj object.Object, node ast.Node) object.Object {
+    if err, ok := obj.(*object.Error); ok && !err.Pos.IsValid() {
+        err.Pos = node.Pos()
+        err.Traceback = append([]*object.Frame{}, frames...)
+    }
+
+    return obj
+}
+
+// isSignal reports whether obj unwinds the enclosing statements, i.e. is
+// a return value, a loop control signal or an error.
+func isSignal(obj object.Object) bool {
+    if obj == nil {
+        return false
+    }
+
+    switch obj.Type() {
+    case object.RETURN_VALUE, object.ERROR_OBJ,
+        object.BREAK_SIGNAL, object.CONTINUE_SIGNAL:
+        return true
+    default:
+        return false
+    }
 }
 
 func isError(obj object.Object) bool {
@@ -1,9 +1,15 @@
 package eval
 
 import (
+	"bytes"
+	"math"
+	"math/cmplx"
+	"os"
+
 	"mxshs/pyinterpreter/lexer"
 	"mxshs/pyinterpreter/object"
 	"mxshs/pyinterpreter/parser"
+	"mxshs/pyinterpreter/token"
 
 	"testing"
 )
@@ -158,6 +164,13 @@ func TestBangOperator(t * testing.T) {
         {"!!true", true},
         {"!69", false},
         {"!!69", true},
+        {"!0", true},
+        {"!\"\"", true},
+        {"![]", true},
+        {"!0.0", true},
+        {"not 1", false},
+        {"not {}", true},
+        {"not 1 == 2", true},
     }
 
     for _, tt := range tests {
@@ -174,11 +187,11 @@ func TestIfStatements(t *testing.T) {
         {"if true: \n\t 69", 69},
         {"if false: 420", nil},
         {"if true: \n\t 69 \n else: 420", 69},
-        {"if false: 69 \n else: \n\t 420", 420},
+        {"if false: 69 \nelse: \n\t 420", 420},
         {"if 1 < 2: \n\t 69", 69},
         {"if 1 > 2: 420", nil}, 
         {"if 1 < 2: \n\t 69 \n else: 420", 69},
-        {"if 1 > 2: 69 \n else: \n\t 420", 420},
+        {"if 1 > 2: 69 \nelse: \n\t 420", 420},
     }
 
     for _, tt := range tests {
@@ -326,12 +339,12 @@ func TestInfixExpressions(t *testing.T) {
         {"69 + 420\n", "489"},
         {"69 - 420\n", "-351"},
         {"69 * 420\n", "28980"},
-        {"5 / 2\n", "2"},
+        {"5 / 2\n", "2.5"},
         {"2 ** 6\n", "64"},
         {"6.9 + 0.42\n", "7.32"},
         {"6.9 - 0.42\n", "6.48"},
         {"5.5 / 2\n", "2.75"},
-        {"5 * 2.2\n", "11"},
+        {"5 * 2.2\n", "11.0"},
         {"\"hello\" + \"world\"", "helloworld"},
         {"true == true", "true"},
         {"false == true", "false"},
@@ -429,27 +442,262 @@ func TestIndexExpression(t *testing.T) {
         },
         {
             "a = [1, 3, 3, 7] \n a[-1]",
-            "null",
+            "7",
         },
         {
             "a = [1, 3, 3, 7] \n a[4]",
-            "null",
+            "IndexError: list index out of range",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func testEval(inp string) object.Object {
+    l := lexer.GetLexer(inp)
+    p := parser.GetParser(l)
+
+    program := p.ParseProgram()
+
+    env := object.NewEnv()
+
+    return Eval(program, env)
+}
+
+// testResult checks what input evaluates to. Expected ints, floats and
+// bools are checked with the typed helpers, strings are compared to the
+// inspected result or to the raised error, without its position.
+func testResult(t *testing.T, input string, expected any) bool {
+    evaluated := testEval(input)
+
+    switch expected := expected.(type) {
+    case int:
+        return testIntegerObject(t, evaluated, int64(expected))
+    case float64:
+        return testFloatObject(t, evaluated, expected)
+    case bool:
+        return testBoolObject(t, evaluated, expected)
+    }
+
+    if err, ok := evaluated.(*object.Error); ok {
+        err.Pos = token.Position{}
+        evaluated = err
+    }
+
+    if evaluated.Inspect() != expected {
+        t.Errorf(
+            "expected result of %q to be: %s, got: %s",
+            input,
+            expected,
+            evaluated.Inspect(),
+        )
+
+        return false
+    }
+
+    return true
+}
+
+
+func TestPrint(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"print(1, \"a\", 2.5)", "1 a 2.5\n"},
+        {"print()", "\n"},
+    }
+
+    for _, tt := range tests {
+        var out bytes.Buffer
+        Stdout = &out
+
+        testEval(tt.input)
+
+        if out.String() != tt.expected {
+            t.Errorf("expected print output: %q, got: %q", tt.expected, out.String())
+        }
+    }
+
+    Stdout = os.Stdout
+}
+
+func TestErrorPositions(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"foo", "1:1: NameError: name is not declared: foo"},
+        {
+            "a = 1\nb = a + \"x\"",
+            "2:5: TypeError: type mismatch in + INTEGER STIRNG",
+        },
+        {
+            "def f(x):\n\treturn x + y\nf(1)",
+            "2:13: NameError: name is not declared: y",
+        },
+        {
+            "len(1, 2)",
+            "1:1: TypeError: expected 1 sequence-like argument, got 2 arguments",
         },
     }
 
     for _, tt := range tests {
         evaluated := testEval(tt.input)
 
-        if isError(evaluated) {
+        err, ok := evaluated.(*object.Error)
+        if !ok {
+            t.Errorf("expected object type: %s, got: %T (%+v)",
+                object.ERROR_OBJ, evaluated, evaluated)
+            continue
+        }
+
+        if err.Inspect() != tt.expected {
+            t.Errorf("expected error: %q, got: %q", tt.expected, err.Inspect())
+        }
+    }
+}
+
+func TestNestedBlocks(t *testing.T) {
+    input := `def p(b, c):
+    if b > c:
+        def g(i, e):
+            return i + e
+        return g(b, c)
+    else:
+        def g(i, e):
+            if i > 1:
+                return i * e
+            return 0
+        return g(b, c)
+p(3, 5) + p(5, 3)
+`
+
+    testIntegerObject(t, testEval(input), 23)
+}
+
+func TestWhileStatements(t *testing.T) {
+    tests := []struct {
+        input string
+        expected int64
+    } {
+        {"i = 0\nwhile i < 5:\n\ti = i + 1\ni", 5},
+        {"i = 0\nwhile i < 5:\n\ti = i + 1\n\tif i == 3: break\ni", 3},
+        {
+            "i = 0\ns = 0\nwhile i < 5:\n\ti = i + 1\n\tif i == 3: continue\n\ts = s + i\ns",
+            12,
+        },
+        {"r = 0\nwhile false: r = 1\nelse: r = 2\nr", 2},
+        {"r = 0\nwhile true:\n\tbreak\nelse: r = 2\nr", 0},
+        {
+            "i = 0\nn = 0\nwhile i < 3:\n\ti = i + 1\n\tj = 0\n\twhile true:\n\t\tj = j + 1\n\t\tn = n + 1\n\t\tif j == 2: break\nn",
+            6,
+        },
+        {"def f():\n\twhile true:\n\t\treturn 7\nf()", 7},
+    }
+
+    for _, tt := range tests {
+        testIntegerObject(t, testEval(tt.input), tt.expected)
+    }
+}
+
+func TestForStatements(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"s = 0\nfor x in [1, 2, 3]:\n\ts = s + x\ns", "6"},
+        {"s = \"\"\nfor c in \"abc\":\n\ts = c + s\ns", "cba"},
+        {"s = 0\nfor i in range(10):\n\tif i == 5: break\n\ts = s + i\ns", "10"},
+        {"s = 0\nfor i in range(5):\n\tif i == 2: continue\n\ts = s + i\ns", "8"},
+        {"r = 0\nfor i in []: r = 1\nelse: r = 2\nr", "2"},
+        {"r = 0\nfor i in [1]:\n\tbreak\nelse: r = 2\nr", "0"},
+        {"def f(xs):\n\tfor x in xs:\n\t\tif x > 1: return x\nf([1, 5, 7])", "5"},
+        {"list(range(0, 10, 3))", "list([0, 3, 6, 9])"},
+        {"list(range(3, 0, -1))", "list([3, 2, 1])"},
+        {"sum(range(5))", "10"},
+        {"sum(\"ab\", \"\")", "ab"},
+        {"len(range(1, 10, 2))", "5"},
+        {"list(range(9223372036854775806, 9223372036854775807, 5))", "list([9223372036854775806])"},
+        {"list(range(-9223372036854775807, -2 ** 63, -3))", "list([-9223372036854775807])"},
+        {"len(range(-2 ** 63, 2 ** 63 - 1, 3))", "6148914691236517205"},
+        {"len(range(10, 0, -3))", "4"},
+        {"r = 0\nif range(-2 ** 63, 2 ** 63 - 1):\n\tr = 1\nr", "1"},
+        {"len(range(-2 ** 63, 2 ** 63 - 1))", "Python int too large to convert to C ssize_t"},
+        {"it = iter([1, 2])\nnext(it)\nnext(it)", "2"},
+        {"for x in 5: x", "'INTEGER' object is not iterable"},
+        {"len(5)", "expected sequence-like argument, got INTEGER argument"},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        if err, ok := evaluated.(*object.Error); ok {
+            if err.Message != tt.expected {
+                t.Errorf("expected error: %q, got: %q", tt.expected, err.Message)
+            }
+            continue
+        }
+
+        if evaluated.Inspect() != tt.expected {
             t.Errorf(
-                "unexpected Error during evaluation: %s",
-                evaluated.(*object.Error).Message,
+                "expected result of for loop to be: %s, got: %s",
+                tt.expected,
+                evaluated.Inspect(),
             )
         }
+    }
+}
+
+func TestDicts(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"{\"a\": 1, \"b\": 2}", "dict({a: 1, b: 2})"},
+        {"d = {\"a\": 1, 2: \"b\"}\nd[2]", "b"},
+        {"d = {1: \"a\"}\nd[true]", "a"},
+        {"d = {1: \"a\"}\nd[1.0]", "a"},
+        {"d = {}\nd[\"x\"] = 5\nd[\"x\"] = 6\nd", "dict({x: 6})"},
+        {"d = {\"x\": {}}\nd[\"x\"][\"y\"] = 1\nd", "dict({x: dict({y: 1})})"},
+        {"d = {\"b\": 1, \"a\": 2}\nd[\"c\"] = 3\nlist(d)", "list([b, a, c])"},
+        {"len({1: 2, 3: 4})", "2"},
+        {"{1: \"a\", 1.0: \"b\", true: \"c\"}", "dict({1: c})"},
+        {"c = {1j: \"a\", (1.8170968107390172e+134+2j): \"b\"}\nlen(c)", "2"},
+        {"c = {1j: \"a\", (1.8170968107390172e+134+2j): \"b\"}\nc[1j]", "a"},
+        {"d = {(1, 2): \"a\"}\nd[(true, 2.0)]", "a"},
+        {"len({2 ** 64: \"big\", 554774489934347788: \"small\"})", "2"},
+        {"2 ** 64 in {554774489934347788: 1}", "false"},
+        {"{2 ** 64: \"a\"}[2.0 ** 64]", "a"},
+        {"{-(2 ** 70): \"a\"}[-(2 ** 70)]", "a"},
+        {"{} == {}", "true"},
+        {"{1: 2, 3: 4} == {3: 4, 1: 2.0}", "true"},
+        {"{1: 2} == {1: 3}", "false"},
+        {"{1: 2} == {2: 2}", "false"},
+        {"{1: 2} != {1: 2, 3: 4}", "true"},
+        {"{\"a\": 1} in [{\"a\": 1}]", "true"},
+        {"{} < {}", "unsupported operand type(s) for <: 'DICT' and 'DICT'"},
+        {"d = {}\nd[\"x\"]", "x"},
+        {"{[1]: 2}", "unhashable type: 'LIST'"},
+        {"d = {}\nd[[1]] = 2", "unhashable type: 'LIST'"},
+        {"a = \"s\"\na[0] = 2", "'STIRNG' object does not support item assignment"},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        if err, ok := evaluated.(*object.Error); ok {
+            if err.Message != tt.expected {
+                t.Errorf("expected error: %q, got: %q", tt.expected, err.Message)
+            }
+            continue
+        }
 
         if evaluated.Inspect() != tt.expected {
             t.Errorf(
-                "expected %s at index, got %s",
+                "expected dict expression to be: %s, got: %s",
                 tt.expected,
                 evaluated.Inspect(),
             )
@@ -457,14 +705,829 @@ func TestIndexExpression(t *testing.T) {
     }
 }
 
-func testEval(inp string) object.Object {
-    l := lexer.GetLexer(inp)
-    p := parser.GetParser(l)
+func TestExceptions(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"r = 0\ntry:\n\traise ValueError\nexcept ValueError:\n\tr = 1\nr", "1"},
+        {"r = 0\ntry:\n\tfoo\nexcept NameError as e:\n\tr = e\nr", "name is not declared: foo"},
+        {"r = 0\ntry:\n\t{}[1]\nexcept LookupError:\n\tr = 2\nr", "2"},
+        {"r = 0\ntry:\n\t1 + \"a\"\nexcept KeyError:\n\tr = 1\nexcept Exception:\n\tr = 2\nr", "2"},
+        {"r = 0\ntry:\n\tr = 1\nexcept:\n\tr = 2\nelse:\n\tr = r + 10\nr", "11"},
+        {"r = 0\ntry:\n\traise TypeError\nexcept:\n\tr = 2\nelse:\n\tr = 3\nr", "2"},
+        {"r = 0\ntry:\n\traise TypeError\nexcept TypeError:\n\tr = 1\nfinally:\n\tr = r + 10\nr", "11"},
+        {"def f():\n\ttry:\n\t\treturn 1\n\tfinally:\n\t\treturn 2\nf()", "2"},
+        {"def f():\n\ttry:\n\t\traise KeyError\n\tfinally:\n\t\treturn 2\nf()", "2"},
+        {"r = 0\nfor i in range(3):\n\ttry:\n\t\tbreak\n\tfinally:\n\t\tr = r + 1\nr", "1"},
+        {"e = ValueError(\"bad\", 2)\ne", "(bad, 2)"},
+        {"try:\n\traise KeyError(\"k\")\nexcept IndexError:\n\tpass", "KeyError: k"},
+        {"try:\n\traise KeyError(\"k\")\nexcept KeyError:\n\traise", "KeyError: k"},
+        {"try:\n\tfoo\nexcept NameError:\n\traise ValueError(\"v\")", "ValueError: v"},
+        {"raise", "RuntimeError: No active exception to reraise"},
+        {"raise 1", "TypeError: exceptions must derive from BaseException"},
+        {
+            "e = ValueError(\"v\")\ntry:\n\traise e\nexcept ValueError as c:\n\tr = c is e\nr",
+            "true",
+        },
+        {
+            "try:\n\tfoo\nexcept 1:\n\tpass",
+            "TypeError: catching classes that do not inherit from BaseException is not allowed",
+        },
+        {"r = 0\ntry:\n\t{}[1]\nexcept (TypeError, LookupError):\n\tr = 1\nr", "1"},
+        {"try:\n\traise ValueError\nexcept (TypeError, KeyError):\n\tpass", "ValueError"},
+        {
+            "try:\n\tfoo\nexcept (NameError, 1):\n\tpass",
+            "TypeError: catching classes that do not inherit from BaseException is not allowed",
+        },
+        {"ZeroDivisionError", "<class 'ZeroDivisionError'>"},
+    }
 
-    program := p.ParseProgram()
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
 
-    env := object.NewEnv()
+func TestTracebacks(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {
+            "foo",
+            "Traceback (most recent call last):\n" +
+            "  File \"t.py\", line 1, in <module>\n" +
+            "NameError: name is not declared: foo",
+        },
+        {
+            "def inner():\n\treturn 1 + \"a\"\ndef outer():\n\treturn inner()\nouter()",
+            "Traceback (most recent call last):\n" +
+            "  File \"t.py\", line 5, in <module>\n" +
+            "  File \"t.py\", line 4, in outer\n" +
+            "  File \"t.py\", line 2, in inner\n" +
+            "TypeError: type mismatch in + INTEGER STIRNG",
+        },
+        {
+            "def f():\n\treturn f()\nf()",
+            "Traceback (most recent call last):\n" +
+            "  File \"t.py\", line 3, in <module>\n" +
+            "  File \"t.py\", line 2, in f\n" +
+            "  File \"t.py\", line 2, in f\n" +
+            "  File \"t.py\", line 2, in f\n" +
+            "  [Previous line repeated 997 more times]\n" +
+            "RecursionError: maximum recursion depth exceeded",
+        },
+    }
 
-    return Eval(program, env)
+    for _, tt := range tests {
+        l := lexer.GetFileLexer("t.py", tt.input)
+        p := parser.GetParser(l)
+
+        evaluated := Eval(p.ParseProgram(), object.NewEnv())
+
+        err, ok := evaluated.(*object.Error)
+        if !ok {
+            t.Errorf("expected object type: %s, got: %T (%+v)",
+                object.ERROR_OBJ, evaluated, evaluated)
+            continue
+        }
+
+        if err.FormatTraceback() != tt.expected {
+            t.Errorf("expected traceback:\n%s\ngot:\n%s", tt.expected, err.FormatTraceback())
+        }
+    }
 }
 
+func TestFunctionArguments(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"def f(a, b=2):\n\treturn [a, b]\nf(1)", "list([1, 2])"},
+        {"def f(a, b=2):\n\treturn [a, b]\nf(b=3, a=1)", "list([1, 3])"},
+        {"def f(*args):\n\treturn args\nf(1, 2)", "tuple((1, 2))"},
+        {"def f(a, **kw):\n\treturn kw\nf(a=1, b=2)", "dict({b: 2})"},
+        {"def f(a, *, b=1):\n\treturn a + b\nf(1, b=5)", "6"},
+        {"def f(a, b, c):\n\treturn a + b + c\nf(*[1, 2], **{\"c\": 3})", "6"},
+        {"x = 1\ndef f(a=x):\n\treturn a\nx = 2\nf()", "1"},
+        {
+            "def f(a, b):\n\treturn a\nf(1)",
+            "TypeError: f() missing 1 required positional argument: 'b'",
+        },
+        {
+            "def f(a, b, c):\n\treturn a\nf()",
+            "TypeError: f() missing 3 required positional arguments: 'a', 'b', and 'c'",
+        },
+        {
+            "def f(*, a):\n\treturn a\nf()",
+            "TypeError: f() missing 1 required keyword-only argument: 'a'",
+        },
+        {
+            "def f(a):\n\treturn a\nf(1, 2)",
+            "TypeError: f() takes 1 positional argument but 2 were given",
+        },
+        {
+            "def f(a, b=1):\n\treturn a\nf(1, 2, 3)",
+            "TypeError: f() takes from 1 to 2 positional arguments but 3 were given",
+        },
+        {
+            "def f():\n\treturn 1\nf(1)",
+            "TypeError: f() takes 0 positional arguments but 1 was given",
+        },
+        {
+            "def f(a):\n\treturn a\nf(1, a=2)",
+            "TypeError: f() got multiple values for argument 'a'",
+        },
+        {
+            "def f(a):\n\treturn a\nf(b=2)",
+            "TypeError: f() got an unexpected keyword argument 'b'",
+        },
+        {
+            "def f(a):\n\treturn a\nf(*1)",
+            "TypeError: argument after * must be an iterable, not INTEGER",
+        },
+        {"len([], a=1)", "TypeError: len() takes no keyword arguments"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestLambdas(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"(lambda: 7)()", "7"},
+        {"f = lambda a, b=1: a + b\nf(2)", "3"},
+        {"def adder(n):\n\treturn lambda x: x + n\nadder(2)(5)", "7"},
+        {"sorted([3, 1, 2])", "list([1, 2, 3])"},
+        {"sorted([3, 1, 2], key=lambda x: -x)", "list([3, 2, 1])"},
+        {
+            "sorted([[1, 1], [0, 2], [1, 3]], key=lambda p: p[0], reverse=true)",
+            "list([list([1, 1]), list([1, 3]), list([0, 2])])",
+        },
+        {"list(map(lambda x: x * 2, [1, 2]))", "list([2, 4])"},
+        {"list(map(lambda a, b: a + b, [1, 2, 3], [10, 20]))", "list([11, 22])"},
+        {
+            "sorted([1], cmp=1)",
+            "TypeError: 'cmp' is an invalid keyword argument for sorted()",
+        },
+        {"list(map(lambda x: x + 1, [\"a\"]))", "TypeError: type mismatch in + STIRNG INTEGER"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestScoping(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"x = 1\ndef f():\n\tx = 2\n\treturn x\nf()\nx", "1"},
+        {"x = 1\ndef f():\n\tglobal x\n\tx = 2\nf()\nx", "2"},
+        {"x = 1\ndef f():\n\treturn x\nf()", "1"},
+        {
+            "def f():\n\tn = 0\n\tdef inc():\n\t\tnonlocal n\n\t\tn = n + 1\n" +
+            "\tinc()\n\tinc()\n\treturn n\nf()",
+            "2",
+        },
+        {
+            "def f():\n\tn = 0\n\tdef g():\n\t\tn = 5\n\tg()\n\treturn n\nf()",
+            "0",
+        },
+        {"def f():\n\tdef g():\n\t\treturn y\n\ty = 3\n\treturn g()\nf()", "3"},
+        {
+            "x = 1\ndef f():\n\ty = x\n\tx = 2\nf()",
+            "UnboundLocalError: cannot access local variable 'x' where it is not associated with a value",
+        },
+        {
+            "def f():\n\tlen([])\n\tlen = 1\nf()",
+            "UnboundLocalError: cannot access local variable 'len' where it is not associated with a value",
+        },
+        {
+            "def f():\n\ttry:\n\t\tx\n\texcept NameError:\n\t\treturn 1\n\tx = 1\nf()",
+            "1",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestClasses(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"class A:\n\tx = 1\nA.x", "1"},
+        {"class A:\n\tpass\na = A()\na.x = 2\na.x", "2"},
+        {
+            "class A:\n\tdef __init__(self, x):\n\t\tself.x = x\n" +
+            "\tdef get(self):\n\t\treturn self.x\nA(3).get()",
+            "3",
+        },
+        {"class A:\n\tdef f(self):\n\t\treturn 1\nA.f(A())", "1"},
+        {"class A:\n\tx = 1\nclass B(A):\n\tpass\nB().x", "1"},
+        {
+            "class A:\n\tdef f(self):\n\t\treturn \"a\"\n" +
+            "class B(A):\n\tdef f(self):\n\t\treturn super().f() + \"b\"\n" +
+            "class C(B):\n\tpass\nC().f()",
+            "ab",
+        },
+        {
+            "class A:\n\tdef __init__(self):\n\t\tself.v = 1\n" +
+            "class B(A):\n\tdef __init__(self):\n\t\tsuper(B, self).__init__()\n" +
+            "\t\tself.w = 2\nb = B()\nb.v + b.w",
+            "3",
+        },
+        {"x = 1\nclass A:\n\tx = 2\n\tdef f(self):\n\t\treturn x\nA().f()", "1"},
+        {"class A:\n\tpass\nA()", "<A object>"},
+        {"class A:\n\tpass\nA().x", "AttributeError: 'A' object has no attribute 'x'"},
+        {"class A:\n\tpass\nA.x", "AttributeError: type object 'A' has no attribute 'x'"},
+        {"class A:\n\tpass\nA(1)", "TypeError: A() takes no arguments"},
+        {
+            "class A:\n\tdef __init__(self):\n\t\treturn 1\nA()",
+            "TypeError: __init__() should return None, not 'INTEGER'",
+        },
+        {
+            "class E(KeyError):\n\tpass\ntry:\n\traise E(\"e\")\nexcept LookupError as e:\n\tr = e\nr",
+            "e",
+        },
+        {
+            "class E(Exception):\n\tdef __init__(self, msg, code):\n" +
+            "\t\tsuper().__init__(msg)\n\t\tself.code = code\n" +
+            "try:\n\traise E(\"bad\", 2)\nexcept E as e:\n\tr = e\nr.code",
+            "2",
+        },
+        {
+            "class E(Exception):\n\tdef __init__(self, code):\n\t\tself.code = code\nraise E(3)",
+            "E: 3",
+        },
+        {
+            "class E(Exception):\n\tdef __init__(self, msg, code):\n" +
+            "\t\tsuper().__init__(msg)\n\t\tself.code = code\nraise E(\"bad\", 2)",
+            "E: bad",
+        },
+        {"e = ValueError(\"v\")\ne.code = 1\ne.code", "1"},
+        {"ValueError(\"v\").code", "AttributeError: 'ValueError' object has no attribute 'code'"},
+        {"class A(1):\n\tpass", "TypeError: base class must be a class, not INTEGER"},
+        {"e = ValueError(\"v\")\nsuper(BaseException, e).__init__(\"w\")\ne", "w"},
+        {"super(BaseException, ValueError(\"v\")).f", "AttributeError: 'super' object has no attribute 'f'"},
+        {"class E(ValueError):\n\tdef f(self):\n\t\treturn 1\nsuper(E, E(\"v\")).f", "AttributeError: 'super' object has no attribute 'f'"},
+        {"ValueError.x", "AttributeError: type object 'ValueError' has no attribute 'x'"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestDunderMethods(t *testing.T) {
+    vector := "class V:\n" +
+        "\tdef __init__(self, x):\n\t\tself.x = x\n" +
+        "\tdef __add__(self, o):\n\t\treturn V(self.x + o.x)\n" +
+        "\tdef __radd__(self, o):\n\t\treturn V(self.x + o)\n" +
+        "\tdef __eq__(self, o):\n\t\treturn self.x == o.x\n" +
+        "\tdef __lt__(self, o):\n\t\treturn self.x < o.x\n" +
+        "\tdef __str__(self):\n\t\treturn \"V\" + str(self.x)\n" +
+        "\tdef __len__(self):\n\t\treturn self.x\n" +
+        "\tdef __getitem__(self, i):\n\t\treturn i * 10\n"
+
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {vector + "str(V(1) + V(2))", "V3"},
+        {vector + "str(5 + V(1))", "V6"},
+        {vector + "str(sum([V(1), V(2)]))", "V3"},
+        {vector + "V(1) == V(1)", "true"},
+        {vector + "V(1) != V(1)", "false"},
+        {vector + "V(2) > V(1)", "true"},
+        {vector + "len(V(4))", "4"},
+        {vector + "V(0)[3]", "30"},
+        {vector + "bool(V(0))", "false"},
+        {vector + "str(sorted([V(2), V(1)])[0])", "V1"},
+        {"class A:\n\tpass\na = A()\na == a", "true"},
+        {"class A:\n\tpass\nA() == A()", "false"},
+        {"class A:\n\tpass\nbool(A())", "true"},
+        {
+            "class A:\n\tdef __add__(self, o):\n\t\treturn NotImplemented\nA() + 1",
+            "TypeError: unsupported operand type(s) for +: 'A' and 'INTEGER'",
+        },
+        {
+            "class A:\n\tpass\nA() < A()",
+            "TypeError: '<' not supported between instances of 'A' and 'A'",
+        },
+        {
+            "class A:\n\tdef __len__(self):\n\t\treturn -1\nlen(A())",
+            "ValueError: __len__() should return >= 0",
+        },
+        {"class A:\n\tpass\nA()[0]", "TypeError: 'A' object is not subscriptable"},
+        {
+            "class C:\n\tdef __init__(self):\n\t\tself.i = 0\n" +
+            "\tdef __iter__(self):\n\t\treturn self\n" +
+            "\tdef __next__(self):\n\t\tif self.i == 3:\n\t\t\traise StopIteration\n" +
+            "\t\tself.i = self.i + 1\n\t\treturn self.i\n" +
+            "r = 0\nfor i in C():\n\tr = r + i\nr",
+            "6",
+        },
+        {
+            "class W:\n\tdef __iter__(self):\n\t\treturn iter([1, 2])\nlist(W())",
+            "list([1, 2])",
+        },
+        {"class A:\n\tpass\nfor x in A():\n\tpass", "TypeError: 'A' object is not iterable"},
+        {
+            "class A:\n\tdef __call__(self, x):\n\t\treturn x + 1\nA()(1)",
+            "2",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestTuples(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"(1, 2)", "tuple((1, 2))"},
+        {"1,", "tuple((1,))"},
+        {"()", "tuple(())"},
+        {"a, b = 1, 2\na, b = b, a\n[a, b]", "list([2, 1])"},
+        {"x, (y, z) = 1, (2, 3)\n[x, y, z]", "list([1, 2, 3])"},
+        {"a, *b = [1, 2, 3]\nb", "list([2, 3])"},
+        {"*a, b, c = range(3)\n[a, b, c]", "list([list([0]), 1, 2])"},
+        {"a, *b, c = 1, 2\nb", "list([])"},
+        {"[a, b] = \"xy\"\nb", "y"},
+        {"r = 0\nfor k, v in [(1, 2), (3, 4)]:\n\tr = r + k * v\nr", "14"},
+        {"def f():\n\treturn 1, 2\nf()", "tuple((1, 2))"},
+        {"def f():\n\treturn\nf()", "null"},
+        {"d = {(1, 2): 3}\nd[(1, 2)]", "3"},
+        {"(1, 2)[1]", "2"},
+        {"(1, 2) + (3,)", "tuple((1, 2, 3))"},
+        {"(1, 2) < (1, 3)", "true"},
+        {"(1, 2) < (1, 2, 0)", "true"},
+        {"(1, (2, 3)) == (1, (2, 3))", "true"},
+        {"[1, 2] != [1, 2]", "false"},
+        {"a, b = 1, 2, 3", "ValueError: too many values to unpack (expected 2)"},
+        {"a, b, c = 1, 2", "ValueError: not enough values to unpack (expected 3, got 2)"},
+        {
+            "a, *b, c = [1]",
+            "ValueError: not enough values to unpack (expected at least 2, got 1)",
+        },
+        {"a, b = 1", "TypeError: cannot unpack non-iterable INTEGER object"},
+        {"{(1, [2]): 3}", "TypeError: unhashable type: 'LIST'"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestAugmentedAssignment(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"x = 1\nx += 2\nx *= 3\nx -= 1\nx", "8"},
+        {"x = 2\nx **= 10\nx //= 3\nx %= 100\nx", "41"},
+        {"x = 12\nx &= 10\nx |= 1\nx ^= 4\nx", "13"},
+        {"x = 1\nx <<= 4\nx >>= 2\nx", "4"},
+        {"x = 7.5\nx //= 2\nx", "3.0"},
+        {
+            "class B:\n\tdef __init__(self):\n\t\tself.v = 1\n" +
+            "\tdef __ilshift__(self, o):\n\t\tself.v = self.v * 10 + o\n\t\treturn self\n" +
+            "b = B()\nb <<= 2\nb.v",
+            "12",
+        },
+        {"s = \"a\"\ns += \"b\"\ns", "ab"},
+        {"t = (1,)\nt += (2,)\nt", "tuple((1, 2))"},
+        {"xs = [1, 2]\nxs[1] += 5\nxs", "list([1, 7])"},
+        {"xs = [1]\nys = xs\nys += (2, 3)\nxs", "list([1, 2, 3])"},
+        {"d = {\"a\": 1}\nd[\"a\"] -= 1\nd", "dict({a: 0})"},
+        {
+            "class P:\n\tdef __init__(self):\n\t\tself.n = 1\np = P()\np.n += 1\np.n",
+            "2",
+        },
+        {
+            "class A:\n\tdef __init__(self):\n\t\tself.n = 0\n" +
+            "\tdef __iadd__(self, o):\n\t\tself.n += o\n\t\treturn self\n" +
+            "a = A()\nb = a\na += 3\nb.n",
+            "3",
+        },
+        {
+            "def f():\n\tc = 0\n\tdef g():\n\t\tnonlocal c\n\t\tc += 1\n\tg()\n\tg()\n\treturn c\nf()",
+            "2",
+        },
+        {"xs = [1, 2]\nxs[0] = 5\nxs", "list([5, 2])"},
+        {"xs = [1, 2]\nxs[-2] = 5\nxs", "list([5, 2])"},
+        {"xs = [1]\nxs[1] = 2", "IndexError: list assignment index out of range"},
+        {"xs = [1]\nxs[1.0] = 2", "TypeError: list indices must be integers or slices, not FLOAT"},
+        {"d = {}\nd[\"k\"] += 1", "KeyError: k"},
+        {
+            "y = 1\ndef f():\n\ty += 1\nf()",
+            "UnboundLocalError: cannot access local variable 'y' where it is not associated with a value",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestSlicing(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"xs = [0, 1, 2, 3, 4, 5]\nxs[1:-1:2]", "list([1, 3])"},
+        {"xs = [0, 1, 2, 3]\nxs[::-1]", "list([3, 2, 1, 0])"},
+        {"xs = [0, 1, 2, 3]\nxs[-2:]", "list([2, 3])"},
+        {"xs = [0, 1, 2, 3, 4, 5]\nxs[5:1:-2]", "list([5, 3])"},
+        {"xs = [0, 1, 2]\nxs[10:]", "list([])"},
+        {"xs = [0, 1, 2]\nxs[-10:2]", "list([0, 1])"},
+        {"xs = [0, 1, 2]\nxs[slice(1, 5)]", "list([1, 2])"},
+        {"(1, 2, 3)[1:]", "tuple((2, 3))"},
+        {"(1, 2, 3)[-3]", "1"},
+        {"\"hello\"[1:3]", "el"},
+        {"\"hello\"[::-1]", "olleh"},
+        {"\"hello\"[-1]", "o"},
+        {"[10, 20][True]", "20"},
+        {"\"ab\"[False]", "a"},
+        {"[0, 1, 2, 3][True:3]", "list([1, 2])"},
+        {"[0, 1, 2][::True]", "list([0, 1, 2])"},
+        {"xs = [1, 2]\nxs[True] = 5\nxs", "list([1, 5])"},
+        {"slice(1, 2, 3)", "slice(1, 2, 3)"},
+        {"xs = [1, 2, 3, 4]\nxs[1:3] = [9]\nxs", "list([1, 9, 4])"},
+        {"xs = [1, 2]\nxs[:0] = (7, 8)\nxs", "list([7, 8, 1, 2])"},
+        {"xs = [1, 2, 3]\nxs[::2] = [0, 0]\nxs", "list([0, 2, 0])"},
+        {"xs = [1, 2, 3]\nxs[:] = []\nxs", "list([])"},
+        {"(1,)[3]", "IndexError: tuple index out of range"},
+        {"\"a\"[-2]", "IndexError: string index out of range"},
+        {"[1][\"a\"]", "TypeError: list indices must be integers or slices, not STIRNG"},
+        {"[1][::0]", "ValueError: slice step cannot be zero"},
+        {"[1][\"a\":]", "TypeError: slice indices must be integers or None"},
+        {
+            "xs = [1, 2, 3]\nxs[::2] = [1]",
+            "ValueError: attempt to assign sequence of size 1 to extended slice of size 2",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestBooleanOperators(t *testing.T) {
+    tests := []struct {
+        input string
+        expected any
+    } {
+        {"1 and 2", 2},
+        {"0 and 2", 0},
+        {"0 or \"x\"", "x"},
+        {"\"\" or 0", 0},
+        {"[] or [1]", "list([1])"},
+        {"1 < 2 and 2 < 3", true},
+        {"false or true and false", false},
+        {"def f():\n\tx.y\n0 and f()", 0},
+        {"def f():\n\tx.y\n1 or f()", 1},
+        {"r = 0\nif (0,):\n\tr = 1\nr", 1},
+        {"r = 0\nxs = [1]\nwhile xs:\n\tr = r + 1\n\txs = []\nr", 1},
+        {"class E:\n\tdef __len__(self):\n\t\treturn 0\nE() or 5", 5},
+        {"class B:\n\tdef __bool__(self):\n\t\treturn false\nnot B()", true},
+        {"class A:\n\tpass\nnot A()", false},
+        {
+            "class X:\n\tdef __bool__(self):\n\t\treturn 1\nif X():\n\tpass",
+            "TypeError: __bool__ should return bool, returned INTEGER",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestComparisons(t *testing.T) {
+    tests := []struct {
+        input string
+        expected any
+    } {
+        {"2 <= 2", true},
+        {"3 >= 4", false},
+        {"i = 3\n0 <= i < 5", true},
+        {"i = 3\n0 <= i < 2", false},
+        {"1 < 2 > 0", true},
+        {"r = []\ndef f(v):\n\tglobal r\n\tr = r + [v]\n\treturn v\nf(1) < f(0) < f(5)\nr", "list([1, 0])"},
+        {"\"a\" < \"b\"", true},
+        {"\"a\" == \"a\"", true},
+        {"1 == \"a\"", false},
+        {"None == None", true},
+        {"2 in [1, 2]", true},
+        {"5 not in (1, 2)", true},
+        {"\"ell\" in \"hello\"", true},
+        {"\"a\" in {\"a\": 1}", true},
+        {"(1, 2) in {(1, 2): 0}", true},
+        {"3 in range(5)", true},
+        {"None in [1, None]", true},
+        {"class C:\n\tdef __contains__(self, x):\n\t\treturn x == 1\n2 not in C()", true},
+        {"x = None\nx is None", true},
+        {"x = None\nx is not None", false},
+        {"True is True", true},
+        {"1 == True", true},
+        {"0 != False", false},
+        {"True < 2", true},
+        {"1.0 == True", true},
+        {"True in [1]", true},
+        {"(1, 2) == (True, 2)", true},
+        {"True + 0 == 1", true},
+        {"True + True", 2},
+        {"-True", -1},
+        {"~False", -1},
+        {"True * 2.5", 2.5},
+        {"True & False", false},
+        {"True | 2", 3},
+        {"[] is []", false},
+        {"1 in 1", "TypeError: argument of type 'INTEGER' is not iterable"},
+        {"1 in \"a\"", "TypeError: 'in <string>' requires string as left operand, not INTEGER"},
+        {"[1] in {}", "TypeError: unhashable type: 'LIST'"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestArithmeticOperators(t *testing.T) {
+    tests := []struct {
+        input string
+        expected any
+    } {
+        {"6 / 3", 2.0},
+        {"7 // 2", 3},
+        {"-7 // 2", -4},
+        {"7 // -2", -4},
+        {"7 % 3", 1},
+        {"-7 % 3", 2},
+        {"7 % -3", -2},
+        {"7.5 // 2", 3.0},
+        {"-7.5 // 2", -4.0},
+        {"-7.5 % 2", 0.5},
+        {"5.5 % -2", -0.5},
+        {"6 & 3", 2},
+        {"6 | 3", 7},
+        {"6 ^ 3", 5},
+        {"~5", -6},
+        {"1 << 4", 16},
+        {"-16 >> 2", -4},
+        {"-1 >> 10", -1},
+        {"1 + 2 << 1", 6},
+        {"1 | 2 ^ 3 & 4", 3},
+        {"2 ** 3 ** 2", 512},
+        {"-2 ** 2", -4},
+        {"2 ** -1", 0.5},
+        {"2.0 ** 0.5", 1.4142135623730951},
+        {"(-8.0) ** 3", -512.0},
+        {"10 ** 0.5 == 10 ** 0.5", true},
+        {"1 / 0", "ZeroDivisionError: division by zero"},
+        {"1.5 / 0", "ZeroDivisionError: float division by zero"},
+        {"1 // 0", "ZeroDivisionError: integer division or modulo by zero"},
+        {"1 % 0", "ZeroDivisionError: integer modulo by zero"},
+        {"1.0 // 0.0", "ZeroDivisionError: float floor division by zero"},
+        {"1.0 % 0", "ZeroDivisionError: float modulo by zero"},
+        {"0 ** -1", "ZeroDivisionError: 0.0 cannot be raised to a negative power"},
+        {"10.0 ** 400", "OverflowError: (34, 'Numerical result out of range')"},
+        {"1 << -1", "ValueError: negative shift count"},
+        {"~1.5", "TypeError: bad operand type for unary ~: 'FLOAT'"},
+        {
+            "class M:\n\tdef __mod__(self, o):\n\t\treturn o\n\tdef __rlshift__(self, o):\n\t\treturn -o\n" +
+            "[M() % 3, 2 << M()]",
+            "list([3, -2])",
+        },
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+
+    // A negative number raised to a fractional power is the principal
+    // complex root.
+    root, ok := testEval("(-8) ** (1 / 3)").(*object.Complex)
+    if !ok || cmplx.Abs(root.Value - complex(1, math.Sqrt(3))) > 1e-12 {
+        t.Errorf("expected (-8) ** (1 / 3) to be (1+1.7320508075688772j), got: %+v", root)
+    }
+}
+
+func TestBigIntegers(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"2 ** 100", "1267650600228229401496703205376"},
+        {"123456789012345678901234567890", "123456789012345678901234567890"},
+        {"9223372036854775807 + 1", "9223372036854775808"},
+        {"-9223372036854775807 - 2", "-9223372036854775809"},
+        {"(9223372036854775807 + 1) - 1 == 9223372036854775807", "true"},
+        {"-(-9223372036854775807 - 1)", "9223372036854775808"},
+        {"3037000500 * 3037000500", "9223372037000250000"},
+        {
+            "def fact(n):\n\tr = 1\n\tfor i in range(1, n + 1):\n\t\tr *= i\n\treturn r\nfact(25)",
+            "15511210043330985984000000",
+        },
+        {"2 ** 100 - 2 ** 100", "0"},
+        {"-(2 ** 70) // 3", "-393530540239137101142"},
+        {"-(2 ** 70) % 3", "2"},
+        {"2 ** 70 / 2 ** 69", "2.0"},
+        {"1 << 100 >> 99", "2"},
+        {"-(1 << 100) >> 1000", "-1"},
+        {"~(2 ** 70)", "-1180591620717411303425"},
+        {"(2 ** 70 | 1) & 3", "1"},
+        {"2 ** 70 > 2 ** 69", "true"},
+        {"2 ** 70 < 1.5", "false"},
+        {"bool(2 ** 64)", "true"},
+        {"{2 ** 70: 1}[2.0 ** 70]", "1"},
+        {"[1, 2, 3][2 ** 70:]", "list([])"},
+        {"int(\"123456789012345678901234567890\")", "123456789012345678901234567890"},
+        {"int(\" -0x_ff \", 16)", "-255"},
+        {"int(\"1_000\")", "1000"},
+        {"int(\"0b101\", 0)", "5"},
+        {"int(-3.9)", "-3"},
+        {"int(100000000000000000000.0)", "100000000000000000000"},
+        {"int(True)", "1"},
+        {"int()", "0"},
+        {"int(\"12a\")", "ValueError: invalid literal for int() with base 10: '12a'"},
+        {"int(\"010\", 0)", "ValueError: invalid literal for int() with base 0: '010'"},
+        {"int(\"1__0\")", "ValueError: invalid literal for int() with base 10: '1__0'"},
+        {"int(\"1\", 40)", "ValueError: int() base must be >= 2 and <= 36, or 0"},
+        {"int(1, 2)", "TypeError: int() can't convert non-string with explicit base"},
+        {"[1][2 ** 70]", "IndexError: cannot fit 'int' into an index-sized integer"},
+        {"range(2 ** 70)", "OverflowError: Python int too large to convert to C ssize_t"},
+        {"2 ** 2000 * 1.0", "OverflowError: int too large to convert to float"},
+        {"2 ** 2000 / 3", "OverflowError: integer division result too large for a float"},
+        {"1 << 2 ** 40", "OverflowError: too many digits in integer"},
+        {"2 ** 70 // 0", "ZeroDivisionError: integer division or modulo by zero"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestStringLiterals(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {`'it\'s' + "\x41é\101"`, "it'sAéA"},
+        {`"""a
+b"""`, "a\nb"},
+        {`r"\n" == "\\n"`, "true"},
+        {`repr("it's") + repr('a\n\x01"')`, `"it's"'a\n\x01"'`},
+        {`b"a\x00\xff"`, `b'a\x00\xff'`},
+        {`[len(b"abc"), b"abc"[1], b"abc"[-1]]`, "list([3, 98, 99])"},
+        {`b"abc"[1:] + b"d"`, `b'bcd'`},
+        {`[98 in b"abc", b"bc" in b"abc", b"a" < b"b", b"a" == "a"]`, "list([true, true, true, false])"},
+        {`list(b"ab")`, "list([97, 98])"},
+        {`{b"k": 1}[b"k"]`, "1"},
+        {`len(b"\N{x}")`, "5"},
+        {`256 in b"a"`, "ValueError: byte must be in range(0, 256)"},
+        {`b"a" - b"b"`, "TypeError: unsupported operand type(s) for -: 'bytes' and 'bytes'"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestFormattedStrings(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {`x = 42
+f"x={x} {x + 1} {{x}}"`, "x=42 43 {x}"},
+        {`s = "hi"
+f"{s!r} {s!s} {'é'!a} {s=} {s = }"`, `'hi' hi '\xe9' s='hi' s = 'hi'`},
+        {`def f(n):
+	return f"n={n:03d}"
+f(7)`, "n=007"},
+        {`f"{42:>5}|{42:<5}|{42:^6}|{-42:05}|{42:+}|{42: }"`, "   42|42   |  42  |-0042|+42| 42"},
+        {`f"{255:x}|{255:#X}|{5:#b}|{8:o}|{1234567:,}|{65535:_x}|{65:c}"`, "ff|0XFF|0b101|10|1,234,567|ffff|A"},
+        {`f"{3.14159:.2f}|{2.5:e}|{0.25:.1%}|{1234.5:,.1f}|{1/3:.3}|{2.0:g}|{-0.5:+.1f}"`, "3.14|2.500000e+00|25.0%|1,234.5|0.333|2|-0.5"},
+        {`f"{'ab':*^6}|{'abc':.2}|{'ab':>4}|{'ab':04}"`, "**ab**|ab|  ab|ab00"},
+        {`w = 6
+p = 2
+f"{3.14159:{w}.{p}f}"`, "  3.14"},
+        {`f"{2 ** 70:,}"`, "1,180,591,620,717,411,303,424"},
+        {`f"{True:d}|{True}"`, "1|true"},
+        {`class P:
+	def __format__(self, spec):
+		return "P" + spec
+f"{P():xyz}"`, "Pxyz"},
+        {`format(3.5, "06.2f") + format(7)`, "003.507"},
+        {`f"{1:s}"`, "ValueError: Unknown format code 's' for object of type 'int'"},
+        {`f"{'a':+}"`, "ValueError: Sign not allowed in string format specifier"},
+        {`f"{1.5:.2d}"`, "ValueError: Unknown format code 'd' for object of type 'float'"},
+        {`f"{1:.2}"`, "ValueError: Precision not allowed in integer format specifier"},
+        {`f"{[]:5}"`, "TypeError: unsupported format string passed to LIST.__format__"},
+        {`f"{1:5xx}"`, "ValueError: Invalid format specifier '5xx' for object of type 'int'"},
+        {`f"{undefined}"`, "NameError: name is not declared: undefined"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestUnicodeStrings(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {`len("héllo")`, "5"},
+        {`len("😀")`, "1"},
+        {`"héllo"[1] + "héllo"[-1]`, "éo"},
+        {`"日本語"[::-1]`, "語本日"},
+        {`"héllo"[1:3]`, "él"},
+        {`list("ñö")`, "list([ñ, ö])"},
+        {`größe = 2
+größe * 3`, "6"},
+        {`add2 = 1
+add2 + 1`, "2"},
+        {`"é" in "café"`, "true"},
+        {`"aé"[2]`, "IndexError: string index out of range"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestNumericLiterals(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"0xff + 0o17 + 0b11", "273"},
+        {"1_000 * 2", "2000"},
+        {"0x1_0000_0000_0000_0000", "18446744073709551616"},
+        {"1e-9", "1e-09"},
+        {"1.5e3", "1500.0"},
+        {"1.0", "1.0"},
+        {"4 / 2", "2.0"},
+        {"5.5 // 2", "2.0"},
+        {"-0.0", "-0.0"},
+        {"1e16", "1e+16"},
+        {"1e400", "inf"},
+        {"3j", "3j"},
+        {"1 + 2j", "(1+2j)"},
+        {"(1 + 2j) * (3 - 1j)", "(5+5j)"},
+        {"2j ** 2", "(-4+0j)"},
+        {"-2j", "(-0-2j)"},
+        {"1j / 2", "0.5j"},
+        {"1 + 0j == 1", "true"},
+        {`{1: "a"}[1 + 0j]`, "a"},
+        {"not 0j", "true"},
+        {"1j / 0", "ZeroDivisionError: complex division by zero"},
+        {"1j < 2", "TypeError: '<' not supported between instances of 'COMPLEX' and 'INTEGER'"},
+        {"1j // 2", "TypeError: unsupported operand type(s) for //: 'COMPLEX' and 'INTEGER'"},
+        {"x = 1 + \\\n    2  # comment\nx", "3"},
+        {"[1,\n 2,\n]", "list([1, 2])"},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
+
+func TestConditionals(t *testing.T) {
+    tests := []struct {
+        input string
+        expected any
+    } {
+        {"x = 0\nif x < 0:\n\tr = 1\nelif x == 0:\n\tr = 2\nelse:\n\tr = 3\nr", 2},
+        {"x = 5\nif x < 0: r = 1\nelif x < 3: r = 2\nelif x < 10: r = 3\nelse: r = 4\nr", 3},
+        {"x = 50\nif x < 0: r = 1\nelif x < 3: r = 2\nelse: r = 4\nr", 4},
+        {"r = 0\nif false: r = 1\nelif false: r = 2\nr", 0},
+        {"if false: 1\nelif undefined: 2", "NameError: name is not declared: undefined"},
+        {"1 if true else 2", 1},
+        {"1 if [] else 2", 2},
+        {"x = 7\n\"odd\" if x % 2 else \"even\"", "odd"},
+        {"0 if false else 1 if false else 2", 2},
+        {"(1 if true else 2) + 10", 11},
+        {"f = lambda n: n if n > 0 else -n\nf(-3)", 3},
+        {"1 if true else undefined", 1},
+        {"undefined if false else 2", 2},
+    }
+
+    for _, tt := range tests {
+        testResult(t, tt.input, tt.expected)
+    }
+}
@@ -0,0 +1,456 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/object"
+)
+
+// evalFormattedString evaluates the parts of an f-string and joins them.
+func evalFormattedString(fs *ast.FormattedString, env *object.Env) object.Object {
+    var out strings.Builder
+
+    for _, part := range fs.Parts {
+        var res object.Object
+
+        if field, ok := part.(*ast.FormattedValue); ok {
+            res = evalFormattedValue(field, env)
+        } else {
+            res = Eval(part, env)
+        }
+
+        if isError(res) {
+            return res
+        }
+
+        out.WriteString(res.(*object.String).Value)
+    }
+
+    return &object.String{Value: out.String()}
+}
+
+func evalFormattedValue(fv *ast.FormattedValue, env *object.Env) object.Object {
+    val := Eval(fv.Value, env)
+    if isError(val) {
+        return val
+    }
+
+    switch fv.Conversion {
+    case "s":
+        val = toStr(val)
+    case "r":
+        val = toRepr(val)
+    case "a":
+        val = toASCII(val)
+    }
+
+    if isError(val) {
+        return locate(val, fv)
+    }
+
+    spec := ""
+    if fv.Spec != nil {
+        res := evalFormattedString(fv.Spec, env)
+        if isError(res) {
+            return res
+        }
+
+        spec = res.(*object.String).Value
+    }
+
+    return locate(formatValue(val, spec), fv)
+}
+
+// toASCII is toRepr with the non-ASCII characters escaped, like ascii().
+func toASCII(obj object.Object) object.Object {
+    res := toRepr(obj)
+
+    str, ok := res.(*object.String)
+    if !ok {
+        return res
+    }
+
+    var out strings.Builder
+
+    for _, r := range str.Value {
+        switch {
+        case r < utf8.RuneSelf:
+            out.WriteRune(r)
+        case r <= 0xff:
+            fmt.Fprintf(&out, "\\x%02x", r)
+        case r <= 0xffff:
+            fmt.Fprintf(&out, "\\u%04x", r)
+        default:
+            fmt.Fprintf(&out, "\\U%08x", r)
+        }
+    }
+
+    return &object.String{Value: out.String()}
+}
+
+// formatSpec is a parsed format spec of the form
+// [[fill]align][sign][#][0][width][grouping][.precision][kind].
+type formatSpec struct {
+    fill rune
+    fillSet bool
+    align rune
+    sign rune
+    alternate bool
+    zero bool
+    width int
+    grouping rune
+    precision int
+    kind rune
+}
+
+// formatValue formats obj with spec the way format() does. Instances can
+// define __format__, ints, floats and strings understand the format spec
+// mini-language and any other object only an empty spec.
+func formatValue(obj object.Object, spec string) object.Object {
+    if res, ok := callMethod(obj, "__format__", &object.String{Value: spec}); ok {
+        if _, ok := res.(*object.String); !ok && !isError(res) {
+            return newError(
+                object.TypeError,
+                "__format__ must return a str, not %s",
+                typeName(res),
+            )
+        }
+
+        return res
+    }
+
+    if spec == "" {
+        return toStr(obj)
+    }
+
+    switch obj := obj.(type) {
+    case *object.Integer:
+        return formatInteger(obj.BigValue(), spec)
+    case *object.Boolean:
+        if obj.Value {
+            return formatInteger(big.NewInt(1), spec)
+        }
+
+        return formatInteger(big.NewInt(0), spec)
+    case *object.Float:
+        return formatFloat(obj.Value, spec)
+    case *object.String:
+        return formatString(obj.Value, spec)
+    default:
+        return newError(
+            object.TypeError,
+            "unsupported format string passed to %s.__format__",
+            typeName(obj),
+        )
+    }
+}
+
+func parseFormatSpec(spec, typ string) (*formatSpec, *object.Error) {
+    fs := &formatSpec{fill: ' ', precision: -1}
+    runes := []rune(spec)
+    i := 0
+
+    isAlign := func(r rune) bool {
+        return strings.ContainsRune("<>=^", r)
+    }
+
+    switch {
+    case len(runes) >= 2 && isAlign(runes[1]):
+        fs.fill, fs.fillSet, fs.align = runes[0], true, runes[1]
+        i = 2
+    case len(runes) >= 1 && isAlign(runes[0]):
+        fs.align = runes[0]
+        i = 1
+    }
+
+    if i < len(runes) && strings.ContainsRune("+- ", runes[i]) {
+        fs.sign = runes[i]
+        i += 1
+    }
+
+    if i < len(runes) && runes[i] == '#' {
+        fs.alternate = true
+        i += 1
+    }
+
+    if i < len(runes) && runes[i] == '0' {
+        fs.zero = true
+        i += 1
+    }
+
+    digits := func() (int, bool) {
+        start := i
+        for i < len(runes) && runes[i] >= '0' && runes[i] <= '9' {
+            i += 1
+        }
+
+        n, err := strconv.Atoi(string(runes[start:i]))
+
+        return n, err == nil
+    }
+
+    fs.width, _ = digits()
+
+    if i < len(runes) && (runes[i] == ',' || runes[i] == '_') {
+        fs.grouping = runes[i]
+        i += 1
+    }
+
+    if i < len(runes) && runes[i] == '.' {
+        i += 1
+
+        precision, ok := digits()
+        if !ok {
+            return nil, newError(
+                object.ValueError,
+                "Format specifier missing precision",
+            )
+        }
+
+        fs.precision = precision
+    }
+
+    switch len(runes) - i {
+    case 0:
+    case 1:
+        fs.kind = runes[i]
+    default:
+        return nil, newError(
+            object.ValueError,
+            "Invalid format specifier '%s' for object of type '%s'",
+            spec,
+            typ,
+        )
+    }
+
+    return fs, nil
+}
+
+func unknownFormatCode(kind rune, typ string) *object.Error {
+    return newError(
+        object.ValueError,
+        "Unknown format code '%c' for object of type '%s'",
+        kind,
+        typ,
+    )
+}
+
+func formatInteger(value *big.Int, spec string) object.Object {
+    fs, err := parseFormatSpec(spec, "int")
+    if err != nil {
+        return err
+    }
+
+    bases := map[rune]int{0: 10, 'd': 10, 'n': 10, 'c': 10, 'b': 2, 'o': 8, 'x': 16, 'X': 16}
+
+    base, ok := bases[fs.kind]
+    if !ok {
+        if strings.ContainsRune("eEfFgG%", fs.kind) {
+            f, _ := new(big.Float).SetInt(value).Float64()
+            return formatFloat(f, spec)
+        }
+
+        return unknownFormatCode(fs.kind, "int")
+    }
+
+    switch {
+    case fs.precision >= 0:
+        return newError(
+            object.ValueError,
+            "Precision not allowed in integer format specifier",
+        )
+    case fs.grouping == ',' && base != 10:
+        return newError(object.ValueError, "Cannot specify ',' with '%c'.", fs.kind)
+    case fs.kind == 'c':
+        if !value.IsInt64() || value.Int64() < 0 || value.Int64() > utf8.MaxRune {
+            return newError(object.OverflowError, "%%c arg not in range(0x110000)")
+        }
+
+        return &object.String{Value: pad("", string(rune(value.Int64())), fs, '>')}
+    }
+
+    digits := new(big.Int).Abs(value).Text(base)
+    if fs.kind == 'X' {
+        digits = strings.ToUpper(digits)
+    }
+
+    if fs.grouping != 0 {
+        size := 4
+        if base == 10 {
+            size = 3
+        }
+
+        digits = groupDigits(digits, fs.grouping, size)
+    }
+
+    prefix := signPrefix(value.Sign() < 0, fs)
+    if fs.alternate && base != 10 {
+        prefix += "0" + string(fs.kind)
+    }
+
+    return &object.String{Value: pad(prefix, digits, fs, '>')}
+}
+
+func formatFloat(value float64, spec string) object.Object {
+    fs, err := parseFormatSpec(spec, "float")
+    if err != nil {
+        return err
+    }
+
+    if fs.kind != 0 && !strings.ContainsRune("eEfFgGn%", fs.kind) {
+        return unknownFormatCode(fs.kind, "float")
+    }
+
+    precision := fs.precision
+    if precision < 0 && fs.kind != 0 {
+        precision = 6
+    }
+
+    abs := math.Abs(value)
+
+    var body string
+
+    switch {
+    case math.IsNaN(value):
+        body = "nan"
+    case math.IsInf(value, 0):
+        body = "inf"
+    case fs.kind == 'f' || fs.kind == 'F':
+        body = strconv.FormatFloat(abs, 'f', precision, 64)
+    case fs.kind == 'e' || fs.kind == 'E':
+        body = strconv.FormatFloat(abs, 'e', precision, 64)
+    case fs.kind == '%':
+        body = strconv.FormatFloat(abs * 100, 'f', precision, 64)
+    case precision < 0:
+        body = (&object.Float{Value: abs}).Inspect()
+    case precision == 0:
+        body = strconv.FormatFloat(abs, 'g', 1, 64)
+    default:
+        body = strconv.FormatFloat(abs, 'g', precision, 64)
+    }
+
+    if strings.ContainsRune("EFG", fs.kind) {
+        body = strings.ToUpper(body)
+    }
+
+    if fs.grouping != 0 {
+        end := strings.IndexFunc(body, func(r rune) bool {
+            return r < '0' || r > '9'
+        })
+        if end == -1 {
+            end = len(body)
+        }
+
+        body = groupDigits(body[:end], fs.grouping, 3) + body[end:]
+    }
+
+    if fs.kind == '%' {
+        body += "%"
+    }
+
+    prefix := signPrefix(math.Signbit(value) && !math.IsNaN(value), fs)
+
+    return &object.String{Value: pad(prefix, body, fs, '>')}
+}
+
+func formatString(value string, spec string) object.Object {
+    fs, err := parseFormatSpec(spec, "str")
+    if err != nil {
+        return err
+    }
+
+    switch {
+    case fs.kind != 0 && fs.kind != 's':
+        return unknownFormatCode(fs.kind, "str")
+    case fs.sign != 0:
+        return newError(object.ValueError, "Sign not allowed in string format specifier")
+    case fs.alternate:
+        return newError(
+            object.ValueError,
+            "Alternate form (#) not allowed in string format specifier",
+        )
+    case fs.align == '=':
+        return newError(
+            object.ValueError,
+            "'=' alignment not allowed in string format specifier",
+        )
+    case fs.grouping != 0:
+        return newError(object.ValueError, "Cannot specify '%c' with 's'.", fs.grouping)
+    }
+
+    if runes := []rune(value); fs.precision >= 0 && fs.precision < len(runes) {
+        value = string(runes[:fs.precision])
+    }
+
+    return &object.String{Value: pad("", value, fs, '<')}
+}
+
+// signPrefix is the sign shown before a number.
+func signPrefix(negative bool, fs *formatSpec) string {
+    switch {
+    case negative:
+        return "-"
+    case fs.sign == '+':
+        return "+"
+    case fs.sign == ' ':
+        return " "
+    default:
+        return ""
+    }
+}
+
+// groupDigits separates the digits in groups of size from the right.
+func groupDigits(digits string, sep rune, size int) string {
+    var out strings.Builder
+
+    for i, digit := range digits {
+        if i != 0 && (len(digits) - i) % size == 0 {
+            out.WriteRune(sep)
+        }
+
+        out.WriteRune(digit)
+    }
+
+    return out.String()
+}
+
+// pad aligns prefix and body within the width of fs, "=" alignment puts
+// the padding between them. The "0" flag pads numbers with zeros after
+// their sign.
+func pad(prefix, body string, fs *formatSpec, align rune) string {
+    fill := fs.fill
+    if fs.zero && !fs.fillSet {
+        fill = '0'
+    }
+
+    if fs.align != 0 {
+        align = fs.align
+    } else if fs.zero && align == '>' {
+        align = '='
+    }
+
+    n := fs.width - utf8.RuneCountInString(prefix + body)
+    if n <= 0 {
+        return prefix + body
+    }
+
+    switch align {
+    case '<':
+        return prefix + body + strings.Repeat(string(fill), n)
+    case '=':
+        return prefix + strings.Repeat(string(fill), n) + body
+    case '^':
+        left := strings.Repeat(string(fill), n / 2)
+        right := strings.Repeat(string(fill), n - n / 2)
+
+        return left + prefix + body + right
+    default:
+        return strings.Repeat(string(fill), n) + prefix + body
+    }
+}
@@ -1,72 +1,76 @@
 package eval
 
 import (
+	"math"
+
 	"mxshs/pyinterpreter/object"
 )
 
-func pow(a int64, b int64) int64 {
-    switch {
-    case b == 0:
-        return int64(1)
-    default:
-        base := int64(1)
-        for b != 0 {
-            if b & 1 != 0 {
-                base *= a
-            }
+// floorDiv divides rounding towards negative infinity, like Python's "//".
+func floorDiv(a, b int64) int64 {
+    q := a / b
+    if a % b != 0 && (a < 0) != (b < 0) {
+        q -= 1
+    }
 
-            a = a * a
-            b >>= 1
-        }
-        
-        return base
+    return q
+}
+
+// floorMod returns the remainder of floorDiv, it has the sign of b.
+func floorMod(a, b int64) int64 {
+    m := a % b
+    if m != 0 && (m < 0) != (b < 0) {
+        m += b
     }
+
+    return m
 }
 
-func powFloat(a, b float64) float64 {
-    switch {
-    case a == 0 || b == 0:
-        return 1
-    default:
-        res := 1.0
-        enum := log(a) * b
-        denom := 1.0
+// floatDivmod returns the floor division and the modulo of two floats the
+// way CPython computes them, so that div * b + mod stays close to a.
+func floatDivmod(a, b float64) (float64, float64) {
+    mod := math.Mod(a, b)
+    div := (a - mod) / b
 
-        for i := 1; i < 65; i += 1 {
-            denom = denom * float64(i)
-            member := floatBasePow(enum, int64(i)) / denom
-            res += member
+    if mod != 0 {
+        if (b < 0) != (mod < 0) {
+            mod += b
+            div -= 1
         }
+    } else {
+        mod = math.Copysign(0, b)
+    }
 
-        return res
+    if div == 0 {
+        return math.Copysign(0, a / b), mod
     }
-}
 
-func log(a float64) float64 {
-    res := 0.0
-    member := (a - 1) / (a + 1)
-    for i := 1; i < 259; i += 2 {
-        res += 1.0 / float64(i) * floatBasePow(member, int64(i))
+    floor := math.Floor(div)
+    if div - floor > 0.5 {
+        floor += 1
     }
 
-    return res * 2
+    return floor, mod
 }
 
-func floatBasePow(a float64, b int64) float64 {
-    if b == 1 {
-        return a
+func floatPow(a, b float64) object.Object {
+    switch {
+    case a == 0 && b < 0:
+        return newError(
+            object.ZeroDivisionError,
+            "0.0 cannot be raised to a negative power",
+        )
+    case a < 0 && b != math.Trunc(b):
+        // Like in Python the result is the principal complex root.
+        return &object.Complex{Value: complexPow(complex(a, 0), complex(b, 0))}
     }
-    base := float64(1)
-    for b != 0 {
-        if b & 1 != 0 {
-            base *= a
-        }
 
-        a = a * a
-        b >>= 1
+    res := math.Pow(a, b)
+    if math.IsInf(res, 0) && !math.IsInf(a, 0) && !math.IsInf(b, 0) {
+        return newError(object.OverflowError, "(34, 'Numerical result out of range')")
     }
 
-    return base
+    return &object.Float{Value: res}
 }
 
 func IsNumeric(obj object.Object) bool {
@@ -79,4 +83,3 @@ func IsNumeric(obj object.Object) bool {
         return false
     }
 }
-
@@ -0,0 +1,287 @@
+package eval
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"mxshs/pyinterpreter/object"
+)
+
+// The int64 fast path of evalIntegerInfixExpression falls back to big ints
+// when one of these reports an overflow.
+
+func addOverflows(a, b int64) bool {
+    return (b > 0 && a > math.MaxInt64 - b) || (b < 0 && a < math.MinInt64 - b)
+}
+
+func subOverflows(a, b int64) bool {
+    return (b < 0 && a > math.MaxInt64 + b) || (b > 0 && a < math.MinInt64 + b)
+}
+
+func mulOverflows(a, b int64) bool {
+    if a == 0 || b == 0 {
+        return false
+    }
+
+    if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+        return true
+    }
+
+    return (a * b) / b != a
+}
+
+// pow raises a to a non-negative power b by squaring, it reports false if
+// the result does not fit in an int64.
+func pow(a, b int64) (int64, bool) {
+    res := int64(1)
+
+    for b > 0 {
+        if b & 1 != 0 {
+            if mulOverflows(res, a) {
+                return 0, false
+            }
+
+            res *= a
+        }
+
+        b >>= 1
+        if b > 0 {
+            if mulOverflows(a, a) {
+                return 0, false
+            }
+
+            a *= a
+        }
+    }
+
+    return res, true
+}
+
+// maxShift bounds the shift count of "<<" on big ints, larger results
+// would not fit in memory anyway.
+const maxShift = 1 << 31
+
+// evalBigIntegerInfixExpression applies op to two ints, at least one of
+// which does not fit in an int64. Results that fit are demoted again.
+func evalBigIntegerInfixExpression(op string, left, right *big.Int) object.Object {
+    switch op {
+    case "+":
+        return object.NewBigInteger(new(big.Int).Add(left, right))
+    case "-":
+        return object.NewBigInteger(new(big.Int).Sub(left, right))
+    case "*":
+        return object.NewBigInteger(new(big.Int).Mul(left, right))
+    case "/":
+        if right.Sign() == 0 {
+            return newError(object.ZeroDivisionError, "division by zero")
+        }
+
+        res, _ := new(big.Rat).SetFrac(left, right).Float64()
+        if math.IsInf(res, 0) {
+            return newError(
+                object.OverflowError,
+                "integer division result too large for a float",
+            )
+        }
+
+        return &object.Float{Value: res}
+    case "//", "%":
+        if right.Sign() == 0 {
+            if op == "//" {
+                return newError(
+                    object.ZeroDivisionError,
+                    "integer division or modulo by zero",
+                )
+            }
+
+            return newError(object.ZeroDivisionError, "integer modulo by zero")
+        }
+
+        div, mod := bigFloorDivMod(left, right)
+        if op == "//" {
+            return object.NewBigInteger(div)
+        }
+
+        return object.NewBigInteger(mod)
+    case "**":
+        if right.Sign() < 0 {
+            leftVal, _ := new(big.Float).SetInt(left).Float64()
+            rightVal, _ := new(big.Float).SetInt(right).Float64()
+
+            return floatPow(leftVal, rightVal)
+        }
+
+        return object.NewBigInteger(new(big.Int).Exp(left, right, nil))
+    case "&":
+        return object.NewBigInteger(new(big.Int).And(left, right))
+    case "|":
+        return object.NewBigInteger(new(big.Int).Or(left, right))
+    case "^":
+        return object.NewBigInteger(new(big.Int).Xor(left, right))
+    case "<<", ">>":
+        if right.Sign() < 0 {
+            return newError(object.ValueError, "negative shift count")
+        }
+
+        huge := !right.IsInt64() || right.Int64() > maxShift
+
+        switch {
+        case left.Sign() == 0:
+            return &object.Integer{Value: 0}
+        case op == "<<" && huge:
+            return newError(object.OverflowError, "too many digits in integer")
+        case op == "<<":
+            return object.NewBigInteger(
+                new(big.Int).Lsh(left, uint(right.Int64())),
+            )
+        case huge && left.Sign() < 0:
+            return &object.Integer{Value: -1}
+        case huge:
+            return &object.Integer{Value: 0}
+        default:
+            // Like the int64 path, this rounds towards negative infinity.
+            return object.NewBigInteger(
+                new(big.Int).Rsh(left, uint(right.Int64())),
+            )
+        }
+    case "<":
+        return nativeBoolToBoolean(left.Cmp(right) < 0)
+    case ">":
+        return nativeBoolToBoolean(left.Cmp(right) > 0)
+    case "<=":
+        return nativeBoolToBoolean(left.Cmp(right) <= 0)
+    case ">=":
+        return nativeBoolToBoolean(left.Cmp(right) >= 0)
+    case "==":
+        return nativeBoolToBoolean(left.Cmp(right) == 0)
+    case "!=":
+        return nativeBoolToBoolean(left.Cmp(right) != 0)
+    default:
+        return newError(
+            object.TypeError,
+            "unknown operator %s for types %s and %s",
+            op,
+            object.INTEGER_OBJ,
+            object.INTEGER_OBJ,
+        )
+    }
+}
+
+// bigFloorDivMod is the big int counterpart of floorDiv and floorMod.
+func bigFloorDivMod(a, b *big.Int) (*big.Int, *big.Int) {
+    div, mod := new(big.Int).QuoRem(a, b, new(big.Int))
+
+    if mod.Sign() != 0 && mod.Sign() != b.Sign() {
+        div.Sub(div, big.NewInt(1))
+        mod.Add(mod, b)
+    }
+
+    return div, mod
+}
+
+// intToFloat converts an int operand of op to a float. Ints too large for a
+// float can still be compared, as infinity, but not used in arithmetic.
+func intToFloat(op string, integer *object.Integer) (*object.Float, *object.Error) {
+    if integer.Big == nil {
+        return &object.Float{Value: float64(integer.Value)}, nil
+    }
+
+    res, _ := new(big.Float).SetInt(integer.Big).Float64()
+
+    switch op {
+    case "<", ">", "<=", ">=", "==", "!=":
+    default:
+        if math.IsInf(res, 0) {
+            return nil, newError(
+                object.OverflowError,
+                "int too large to convert to float",
+            )
+        }
+    }
+
+    return &object.Float{Value: res}, nil
+}
+
+// floatToInt truncates a finite float towards zero.
+func floatToInt(f float64) *object.Integer {
+    if f >= math.MinInt64 && f < math.MaxInt64 {
+        return &object.Integer{Value: int64(f)}
+    }
+
+    res, _ := big.NewFloat(f).Int(nil)
+
+    return object.NewBigInteger(res)
+}
+
+// indexValue converts an int used as a position to an int64, big ints are
+// out of the range of any sequence.
+func indexValue(integer *object.Integer) (int64, *object.Error) {
+    if integer.Big != nil {
+        return 0, newError(
+            object.IndexError,
+            "cannot fit 'int' into an index-sized integer",
+        )
+    }
+
+    return integer.Value, nil
+}
+
+// parseInt parses an int literal in base like int() does: surrounded by
+// whitespace, with an optional sign, underscores between digits and, for
+// base 0 or the matching base, a 0x, 0o or 0b prefix. Base 0 guesses the
+// base from the prefix and, as in source code, rejects leading zeros.
+func parseInt(s string, base int) (*object.Integer, bool) {
+    s = strings.TrimSpace(s)
+
+    negative := false
+    if s != "" && (s[0] == '+' || s[0] == '-') {
+        negative = s[0] == '-'
+        s = s[1:]
+    }
+
+    prefixed := false
+    if len(s) > 1 && s[0] == '0' {
+        prefixes := map[byte]int{'x': 16, 'o': 8, 'b': 2}
+
+        prefixBase, ok := prefixes[s[1] | 0x20]
+        if ok && (base == 0 || base == prefixBase) {
+            base = prefixBase
+            s = s[2:]
+            prefixed = true
+        }
+    }
+
+    // A single underscore may follow the prefix or separate two digits.
+    if prefixed && strings.HasPrefix(s, "_") {
+        s = s[1:]
+    }
+
+    if s == "" || s[0] == '_' || s[len(s) - 1] == '_' || strings.Contains(s, "__") {
+        return nil, false
+    }
+
+    digits := strings.ReplaceAll(s, "_", "")
+    if digits[0] == '+' || digits[0] == '-' {
+        return nil, false
+    }
+
+    if base == 0 {
+        base = 10
+
+        if digits[0] == '0' && strings.Trim(digits, "0") != "" {
+            return nil, false
+        }
+    }
+
+    res, ok := new(big.Int).SetString(digits, base)
+    if !ok {
+        return nil, false
+    }
+
+    if negative {
+        res.Neg(res)
+    }
+
+    return object.NewBigInteger(res), true
+}
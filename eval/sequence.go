@@ -0,0 +1,586 @@
+package eval
+
+import (
+	"math"
+	"strings"
+
+	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/object"
+)
+
+// isSequence reports whether obj is a list or a tuple.
+func isSequence(obj object.Object) bool {
+    switch obj.(type) {
+    case *object.List, *object.Tuple:
+        return true
+    default:
+        return false
+    }
+}
+
+// sequenceElements returns the elements of a list or a tuple.
+func sequenceElements(obj object.Object) []object.Object {
+    switch obj := obj.(type) {
+    case *object.List:
+        return obj.Arr
+    case *object.Tuple:
+        return obj.Elements
+    default:
+        return nil
+    }
+}
+
+// sequenceName is the name of a list, a tuple, a string or bytes in error
+// messages.
+func sequenceName(obj object.Object) string {
+    switch obj.(type) {
+    case *object.List:
+        return "list"
+    case *object.Tuple:
+        return "tuple"
+    case *object.Bytes:
+        return "bytes"
+    default:
+        return "string"
+    }
+}
+
+func sequenceLen(obj object.Object) int64 {
+    switch obj := obj.(type) {
+    case *object.String:
+        return int64(obj.Len())
+    case *object.Bytes:
+        return int64(len(obj.Value))
+    default:
+        return int64(len(sequenceElements(obj)))
+    }
+}
+
+// newSequence returns a sequence of the same type as like holding elements.
+func newSequence(like object.Object, elements []object.Object) object.Object {
+    if _, ok := like.(*object.Tuple); ok {
+        return &object.Tuple{Elements: elements}
+    }
+
+    return &object.List{Arr: elements}
+}
+
+// evalSequenceInfixExpression applies op to two lists or two tuples.
+func evalSequenceInfixExpression(
+    op string, left, right object.Object) object.Object {
+
+    leftElements, rightElements := sequenceElements(left), sequenceElements(right)
+
+    switch op {
+    case "+":
+        elements := append([]object.Object{}, leftElements...)
+        return newSequence(left, append(elements, rightElements...))
+    case "==", "!=", "<", ">", "<=", ">=":
+        return compareSequences(op, leftElements, rightElements)
+    default:
+        return newError(
+            object.TypeError,
+            "unsupported operand type(s) for %s: '%s' and '%s'",
+            op,
+            left.Type(),
+            right.Type(),
+        )
+    }
+}
+
+// compareSequences compares two sequences lexicographically: the first
+// elements that differ decide, otherwise the shorter sequence is smaller.
+func compareSequences(op string, left, right []object.Object) object.Object {
+    for i := 0; i < len(left) && i < len(right); i++ {
+        equal := evalInfixExpression("==", left[i], right[i])
+        if isError(equal) {
+            return equal
+        }
+
+        truth, err := checkCondition(equal)
+        if err != nil {
+            return err
+        }
+
+        if truth {
+            continue
+        }
+
+        switch op {
+        case "==":
+            return FALSE
+        case "!=":
+            return TRUE
+        default:
+            return evalInfixExpression(op, left[i], right[i])
+        }
+    }
+
+    return evalIntegerInfixExpression(
+        op,
+        &object.Integer{Value: int64(len(left))},
+        &object.Integer{Value: int64(len(right))},
+    )
+}
+
+// evalMembership tests whether item is in container. Instances can define
+// __contains__, strings look for a substring, dicts for a key and other
+// iterables for an item equal to item.
+func evalMembership(item, container object.Object) object.Object {
+    if res, ok := callMethod(container, "__contains__", item); ok {
+        return truthValue(res)
+    }
+
+    switch container := container.(type) {
+    case *object.String:
+        str, ok := item.(*object.String)
+        if !ok {
+            return newError(
+                object.TypeError,
+                "'in <string>' requires string as left operand, not %s",
+                typeName(item),
+            )
+        }
+
+        return nativeBoolToBoolean(strings.Contains(container.Value, str.Value))
+    case *object.Bytes:
+        switch item := item.(type) {
+        case *object.Bytes:
+            return nativeBoolToBoolean(strings.Contains(container.Value, item.Value))
+        case *object.Integer:
+            if item.Big != nil || item.Value < 0 || item.Value > 255 {
+                return newError(object.ValueError, "byte must be in range(0, 256)")
+            }
+
+            return nativeBoolToBoolean(
+                strings.IndexByte(container.Value, byte(item.Value)) != -1,
+            )
+        default:
+            return newError(
+                object.TypeError,
+                "a bytes-like object is required, not '%s'",
+                typeName(item),
+            )
+        }
+    case *object.Dict:
+        key, err := toHashable(item)
+        if err != nil {
+            return err
+        }
+
+        _, ok := container.Get(key)
+
+        return nativeBoolToBoolean(ok)
+    }
+
+    iterator, err := getIterator(container)
+    if err != nil {
+        return newError(
+            object.TypeError,
+            "argument of type '%s' is not iterable",
+            typeName(container),
+        )
+    }
+
+    for {
+        elem, ok := iterator.Next()
+        if !ok {
+            return FALSE
+        }
+
+        if isError(elem) {
+            return elem
+        }
+
+        if elem == item {
+            return TRUE
+        }
+
+        equal := evalInfixExpression("==", elem, item)
+        if isError(equal) {
+            return equal
+        }
+
+        truth, err := checkCondition(equal)
+        if err != nil {
+            return err
+        }
+
+        if truth {
+            return TRUE
+        }
+    }
+}
+
+// evalSequenceIndexExpression indexes a list, a tuple, a string or bytes
+// with an integer, negative ones counting from the end, or with a slice.
+// Booleans index like the ints 0 and 1.
+func evalSequenceIndexExpression(sequence, index object.Object) object.Object {
+    length := sequenceLen(sequence)
+
+    switch index := boolToInteger(index).(type) {
+    case *object.Integer:
+        i, err := indexValue(index)
+        if err != nil {
+            return err
+        }
+
+        i, ok := normalizeIndex(i, length)
+        if !ok {
+            return newError(
+                object.IndexError,
+                "%s index out of range",
+                sequenceName(sequence),
+            )
+        }
+
+        switch sequence := sequence.(type) {
+        case *object.String:
+            // Strings are indexed by code point.
+            return &object.String{Value: string([]rune(sequence.Value)[i])}
+        case *object.Bytes:
+            return &object.Integer{Value: int64(sequence.Value[i])}
+        default:
+            return sequenceElements(sequence)[i]
+        }
+    case *object.Slice:
+        start, stop, step, err := sliceIndices(index, length)
+        if err != nil {
+            return err
+        }
+
+        return sliceSequence(sequence, sliceRange(start, stop, step))
+    default:
+        return newError(
+            object.TypeError,
+            "%s indices must be integers or slices, not %s",
+            sequenceName(sequence),
+            typeName(index),
+        )
+    }
+}
+
+// normalizeIndex resolves a negative index from the end of a sequence of
+// length items and reports whether it is in range.
+func normalizeIndex(index, length int64) (int64, bool) {
+    if index < 0 {
+        index += length
+    }
+
+    return index, index >= 0 && index < length
+}
+
+// evalSliceExpression evaluates the parts of a slice, the omitted ones are
+// None.
+func evalSliceExpression(se *ast.SliceExpression, env *object.Env) object.Object {
+    parts := []object.Object{}
+
+    for _, part := range []ast.Expression{se.Start, se.Stop, se.Step} {
+        if part == nil {
+            parts = append(parts, NULL)
+            continue
+        }
+
+        evaluated := Eval(part, env)
+        if isError(evaluated) {
+            return evaluated
+        }
+
+        parts = append(parts, evaluated)
+    }
+
+    return &object.Slice{Start: parts[0], Stop: parts[1], Step: parts[2]}
+}
+
+// sliceIndices resolves the bounds of slice for a sequence of length
+// items, like Python's slice.indices: out of range bounds are clamped and
+// the omitted ones depend on the direction of the step.
+func sliceIndices(
+    slice *object.Slice, length int64) (int64, int64, int64, *object.Error) {
+
+    step := int64(1)
+
+    if slice.Step != NULL {
+        var err *object.Error
+
+        step, err = sliceIndex(slice.Step)
+        if err != nil {
+            return 0, 0, 0, err
+        }
+
+        if step == 0 {
+            return 0, 0, 0, newError(
+                object.ValueError,
+                "slice step cannot be zero",
+            )
+        }
+    }
+
+    lower, upper := int64(0), length
+    if step < 0 {
+        lower, upper = -1, length - 1
+    }
+
+    bounds := []int64{lower, upper}
+    if step < 0 {
+        bounds[0], bounds[1] = upper, lower
+    }
+
+    for i, bound := range []object.Object{slice.Start, slice.Stop} {
+        if bound == NULL {
+            continue
+        }
+
+        index, err := sliceIndex(bound)
+        if err != nil {
+            return 0, 0, 0, err
+        }
+
+        if index < 0 {
+            index += length
+        }
+
+        switch {
+        case index < lower:
+            index = lower
+        case index > upper:
+            index = upper
+        }
+
+        bounds[i] = index
+    }
+
+    return bounds[0], bounds[1], step, nil
+}
+
+func sliceIndex(obj object.Object) (int64, *object.Error) {
+    integer, ok := boolToInteger(obj).(*object.Integer)
+    if !ok {
+        return 0, newError(
+            object.TypeError,
+            "slice indices must be integers or None",
+        )
+    }
+
+    // Big ints are past either end of any sequence, so they are clamped.
+    if integer.Big != nil {
+        if integer.Big.Sign() < 0 {
+            return math.MinInt64, nil
+        }
+
+        return math.MaxInt64, nil
+    }
+
+    return integer.Value, nil
+}
+
+// sliceRange returns the indices selected by resolved slice bounds.
+func sliceRange(start, stop, step int64) []int64 {
+    indices := []int64{}
+
+    for i := start; (step > 0 && i < stop) || (step < 0 && i > stop); i += step {
+        indices = append(indices, i)
+    }
+
+    return indices
+}
+
+// sliceSequence returns a sequence of the same type as sequence holding
+// the items at indices.
+func sliceSequence(sequence object.Object, indices []int64) object.Object {
+    switch sequence := sequence.(type) {
+    case *object.String:
+        runes := []rune(sequence.Value)
+        res := make([]rune, 0, len(indices))
+
+        for _, i := range indices {
+            res = append(res, runes[i])
+        }
+
+        return &object.String{Value: string(res)}
+    case *object.Bytes:
+        var out strings.Builder
+
+        for _, i := range indices {
+            out.WriteByte(sequence.Value[i])
+        }
+
+        return &object.Bytes{Value: out.String()}
+    }
+
+    elements := sequenceElements(sequence)
+    res := make([]object.Object, 0, len(indices))
+
+    for _, i := range indices {
+        res = append(res, elements[i])
+    }
+
+    return newSequence(sequence, res)
+}
+
+// assignSlice replaces the items of list selected by slice with the items
+// of iterable. A slice with a step other than 1 has to be replaced by as
+// many items as it selects.
+func assignSlice(
+    list *object.List, slice *object.Slice, iterable object.Object) object.Object {
+
+    start, stop, step, err := sliceIndices(slice, int64(len(list.Arr)))
+    if err != nil {
+        return err
+    }
+
+    items, err := iterableItems(iterable)
+    if err != nil {
+        return err
+    }
+
+    if step == 1 {
+        if stop < start {
+            stop = start
+        }
+
+        arr := append([]object.Object{}, list.Arr[:start]...)
+        arr = append(arr, items...)
+        list.Arr = append(arr, list.Arr[stop:]...)
+
+        return NULL
+    }
+
+    indices := sliceRange(start, stop, step)
+    if len(indices) != len(items) {
+        return newError(
+            object.ValueError,
+            "attempt to assign sequence of size %d to extended slice of size %d",
+            len(items),
+            len(indices),
+        )
+    }
+
+    for j, i := range indices {
+        list.Arr[i] = items[j]
+    }
+
+    return NULL
+}
+
+// iterableItems collects the items of an iterable.
+func iterableItems(iterable object.Object) ([]object.Object, *object.Error) {
+    iterator, err := getIterator(iterable)
+    if err != nil {
+        return nil, err
+    }
+
+    items := []object.Object{}
+
+    for {
+        item, ok := iterator.Next()
+        if !ok {
+            return items, nil
+        }
+
+        if err, ok := item.(*object.Error); ok {
+            return nil, err
+        }
+
+        items = append(items, item)
+    }
+}
+
+// extendList appends the items of iterable to list, as in "xs += ys".
+func extendList(list *object.List, iterable object.Object) object.Object {
+    items, err := iterableItems(iterable)
+    if err != nil {
+        return err
+    }
+
+    list.Arr = append(list.Arr, items...)
+
+    return list
+}
+
+// unpackTargets assigns the items of val to targets, a starred target
+// collects the items left over by the others into a list.
+func unpackTargets(
+    targets []ast.Expression, val object.Object, env *object.Env) object.Object {
+
+    starred := -1
+    for i, target := range targets {
+        prefix, ok := target.(*ast.PrefixExpression)
+        if ok && prefix.Operator == "*" {
+            starred = i
+        }
+    }
+
+    iterator, err := getIterator(val)
+    if err != nil {
+        return newError(
+            object.TypeError,
+            "cannot unpack non-iterable %s object",
+            typeName(val),
+        )
+    }
+
+    items := []object.Object{}
+
+    // Without a starred target, one item more than expected is enough to
+    // fail, even if the iterator never ends.
+    for starred != -1 || len(items) <= len(targets) {
+        item, ok := iterator.Next()
+        if !ok {
+            break
+        }
+
+        if isError(item) {
+            return item
+        }
+
+        items = append(items, item)
+    }
+
+    switch {
+    case starred != -1 && len(items) < len(targets) - 1:
+        return newError(
+            object.ValueError,
+            "not enough values to unpack (expected at least %d, got %d)",
+            len(targets) - 1,
+            len(items),
+        )
+    case starred == -1 && len(items) > len(targets):
+        return newError(
+            object.ValueError,
+            "too many values to unpack (expected %d)",
+            len(targets),
+        )
+    case starred == -1 && len(items) < len(targets):
+        return newError(
+            object.ValueError,
+            "not enough values to unpack (expected %d, got %d)",
+            len(targets),
+            len(items),
+        )
+    }
+
+    rest := len(items) - len(targets) + 1
+
+    for i, target := range targets {
+        var item object.Object
+
+        switch {
+        case starred == -1 || i < starred:
+            item = items[i]
+        case i == starred:
+            item = &object.List{
+                Arr: append([]object.Object{}, items[i:i + rest]...),
+            }
+            target = target.(*ast.PrefixExpression).Right
+        default:
+            item = items[i + rest - 1]
+        }
+
+        if res := assignTarget(target, item, env); isError(res) {
+            return res
+        }
+    }
+
+    return NULL
+}
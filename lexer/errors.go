@@ -0,0 +1,133 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"mxshs/pyinterpreter/token"
+)
+
+// errorClasses are the classes of errors found in the source.
+var errorClasses = []string{"SyntaxError", "IndentationError", "TabError"}
+
+// SyntaxError is an error found in the source while lexing or parsing it.
+type SyntaxError struct {
+    Pos token.Position
+    // Class is SyntaxError, or IndentationError and TabError for errors in
+    // the indentation.
+    Class string
+    Message string
+    // Expected is the type of token that was expected when Found was read
+    // instead, both are empty unless the error is an unexpected token.
+    Expected token.TokenType
+    Found *token.Token
+}
+
+// NewSyntaxError returns the error described by msg, which may start with
+// the class of the error, e.g. "IndentationError: unexpected indent".
+// Without a class it is a SyntaxError.
+func NewSyntaxError(pos token.Position, msg string) *SyntaxError {
+    for _, class := range errorClasses {
+        if rest, ok := strings.CutPrefix(msg, class + ": "); ok {
+            return &SyntaxError{Pos: pos, Class: class, Message: rest}
+        }
+    }
+
+    return &SyntaxError{Pos: pos, Class: "SyntaxError", Message: msg}
+}
+
+// Error returns the error prefixed with its position, e.g.
+// "test.py:1:7: SyntaxError: invalid syntax".
+func (e *SyntaxError) Error() string {
+    msg := e.Class + ": " + e.Message
+    if e.Pos.IsValid() {
+        msg = e.Pos.String() + ": " + msg
+    }
+
+    return msg
+}
+
+// FormatExcerpt formats the error the way Python reports it, with the
+// line of source it was found in and a caret under its position:
+//
+//      File "test.py", line 1
+//        x = 1 $ 2
+//              ^
+//    SyntaxError: invalid syntax
+func (e *SyntaxError) FormatExcerpt(source string) string {
+    var out strings.Builder
+
+    if e.Pos.Filename != "" {
+        fmt.Fprintf(&out, "  File \"%s\", line %d\n", e.Pos.Filename, e.Pos.Line)
+    }
+
+    if e.Pos.IsValid() && e.Pos.Offset <= len(source) {
+        start := strings.LastIndexByte(source[:e.Pos.Offset], '\n') + 1
+
+        end := strings.IndexByte(source[start:], '\n')
+        if end == -1 {
+            end = len(source)
+        } else {
+            end += start
+        }
+
+        line := strings.TrimRight(source[start:end], "\r")
+        if start == 0 {
+            line = strings.TrimPrefix(line, byteOrderMark)
+        }
+        text := strings.TrimLeft(line, " \t\f")
+
+        // The caret is under the error's column, less the indentation that
+        // isn't shown.
+        caret := e.Pos.Column - 1 - utf8.RuneCountInString(line[:len(line) - len(text)])
+        if caret < 0 {
+            caret = 0
+        }
+
+        fmt.Fprintf(&out, "    %s\n    %s^\n", text, strings.Repeat(" ", caret))
+    }
+
+    out.WriteString(e.Class + ": " + e.Message)
+
+    return out.String()
+}
+
+// Describe describes a token for error messages, e.g. "')'" or "end of
+// line".
+func Describe(tok token.Token) string {
+    switch tok.Type {
+    case token.NEWL:
+        return "end of line"
+    case token.EOF:
+        return "end of file"
+    case token.INDENT:
+        return "indent"
+    case token.DEDENT:
+        return "dedent"
+    default:
+        return "'" + tok.Literal + "'"
+    }
+}
+
+// DescribeType describes a type of token for error messages, e.g. "':'"
+// or "a name".
+func DescribeType(t token.TokenType) string {
+    switch t {
+    case token.NAME:
+        return "a name"
+    case token.INT, token.FLOAT, token.IMAG:
+        return "a number"
+    case token.STRING, token.BYTES, token.FSTRING:
+        return "a string"
+    case token.NEWL, token.EOF, token.INDENT, token.DEDENT:
+        return Describe(token.Token{Type: t})
+    }
+
+    // Keywords are named by their type, which is the keyword in upper case.
+    if keyword := strings.ToLower(string(t)); token.LookupKey(keyword) == t {
+        return "'" + keyword + "'"
+    }
+
+    return "'" + string(t) + "'"
+}
@@ -2,48 +2,160 @@ package lexer
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"mxshs/pyinterpreter/token"
 )
 
+// tabSize is the width of a tab when measuring indentation.
+const tabSize = 8
+
 type Lexer struct {
     input string
+    filename string
     position int
     readPosition int
-    ch byte
-    depth int
+    // ch is the current character, position is the byte offset of it and
+    // readPosition the one of the next character.
+    ch rune
+    line int
+    column int
     inputSize int
+    // offset is the offset of the input in the source it was taken from.
+    offset int
+
+    // indents is the stack of indentation levels of the enclosing blocks,
+    // atLineStart is set while the indentation of a new line is pending.
+    indents []indent
+    atLineStart bool
+    // pending holds tokens that are already lexed but not yet returned,
+    // e.g. a run of DEDENT tokens.
+    pending []token.Token
+    prevType token.TokenType
+    // parens is the number of open brackets, newlines inside brackets
+    // don't end the logical line.
+    parens int
+    errors []*SyntaxError
+}
+
+// indent is an indentation level measured with tabs expanded to tabSize
+// columns (col) and to a single column (alt). Both must agree on the
+// ordering of levels, otherwise tabs and spaces are mixed inconsistently.
+type indent struct {
+    col int
+    alt int
 }
 
 func GetLexer(input string) *Lexer {
-    l := &Lexer{input: input, inputSize: len(input)}
+    return GetFileLexer("", input)
+}
+
+// byteOrderMark is the UTF-8 byte order mark some editors start files with.
+const byteOrderMark = "\uFEFF"
+
+// GetFileLexer returns a lexer whose token positions refer to filename. A
+// leading byte order mark is skipped, offsets still count its bytes.
+func GetFileLexer(filename, input string) *Lexer {
+    l := &Lexer{
+        input: input,
+        filename: filename,
+        inputSize: len(input),
+        line: 1,
+        indents: []indent{{}},
+        atLineStart: true,
+    }
+
+    if strings.HasPrefix(input, byteOrderMark) {
+        l.readPosition = len(byteOrderMark)
+    }
+
+    l.nextChar()
+    return l
+}
+
+// GetLexerAt returns a lexer for a piece of a larger source that starts at
+// pos, like an expression in an f-string, so that its token positions refer
+// to the larger source.
+func GetLexerAt(pos token.Position, input string) *Lexer {
+    l := &Lexer{
+        input: input,
+        filename: pos.Filename,
+        inputSize: len(input),
+        line: pos.Line,
+        column: pos.Column - 1,
+        offset: pos.Offset,
+        indents: []indent{{}},
+    }
     l.nextChar()
     return l
 }
 
+func (l *Lexer) Errors() []string {
+    errors := []string{}
+    for _, err := range l.errors {
+        errors = append(errors, err.Error())
+    }
+
+    return errors
+}
+
+func (l *Lexer) SyntaxErrors() []*SyntaxError {
+    return l.errors
+}
+
 func (l *Lexer) nextChar() {
+    if l.ch == '\n' {
+        l.line += 1
+        l.column = 1
+    } else {
+        l.column += 1
+    }
+
+    size := 0
     if l.readPosition >= l.inputSize {
         l.ch = 0
     } else {
-        l.ch = l.input[l.readPosition]
+        l.ch, size = utf8.DecodeRuneInString(l.input[l.readPosition:])
     }
     l.position = l.readPosition
-    l.readPosition += 1
+    l.readPosition += size
 }
 
 func (l *Lexer) NextToken() token.Token {
+    if len(l.pending) == 0 {
+        l.readToken()
+    }
+
+    tok := l.pending[0]
+    l.pending = l.pending[1:]
+    l.prevType = tok.Type
+
+    return tok
+}
+
+// readToken lexes the next token(s) from the input onto the pending queue.
+func (l *Lexer) readToken() {
     var tok token.Token
 
+    if l.atLineStart {
+        l.atLineStart = false
+        l.readIndentation()
+
+        if len(l.pending) != 0 {
+            return
+        }
+    }
+
     l.omitSymbol()
 
+    pos := l.pos()
+
     switch l.ch {
     case '\n':
-        depth := 0
-        for l.peekChar() == ' ' || l.peekChar() == '\t' {
-            depth += 1
-            l.nextChar()
-        }
-        l.depth = depth
         tok = newToken(token.NEWL, l.ch)
+        l.atLineStart = true
     case '=':
         if l.peekChar() == '=' {
             ch := l.ch
@@ -76,20 +188,33 @@ func (l *Lexer) NextToken() token.Token {
             l.nextChar()
             tok = token.Token{Type: token.TIMES_ASSIGN, Literal: string(ch) + string(l.ch)}
         case '*':
-            ch := l.ch
             l.nextChar()
-            tok = token.Token{Type: token.DOUBLE_STAR, Literal: string(ch) + string(l.ch)}
+            tok = l.withAssign(token.DOUBLE_STAR, token.POW_ASSIGN)
         default:
             tok = newToken(token.STAR, l.ch)
         }
     case '/':
-        if l.peekChar() == '=' {
+        switch l.peekChar() {
+        case '=':
             ch := l.ch
             l.nextChar()
             tok = token.Token{Type: token.DIV_ASSIGN, Literal: string(ch) + string(l.ch)}
-        } else {
+        case '/':
+            l.nextChar()
+            tok = l.withAssign(token.DOUBLE_SLASH, token.FLOOR_DIV_ASSIGN)
+        default:
             tok = newToken(token.SLASH, l.ch)
         }
+    case '%':
+        tok = l.withAssign(token.PERCENT, token.MOD_ASSIGN)
+    case '&':
+        tok = l.withAssign(token.AMPERSAND, token.AND_ASSIGN)
+    case '|':
+        tok = l.withAssign(token.PIPE, token.OR_ASSIGN)
+    case '^':
+        tok = l.withAssign(token.CARET, token.XOR_ASSIGN)
+    case '~':
+        tok = newToken(token.TILDE, l.ch)
     case '!':
         if l.peekChar() == '=' {
             ch := l.ch
@@ -99,144 +224,538 @@ func (l *Lexer) NextToken() token.Token {
             tok = newToken(token.BANG, l.ch)
         }
     case '<':
-        if l.peekChar() == '=' {
+        switch l.peekChar() {
+        case '=':
             ch := l.ch
             l.nextChar()
             tok = token.Token{Type: token.LESS_EQ, Literal: string(ch) + string(l.ch)}
-        } else {
+        case '<':
+            l.nextChar()
+            tok = l.withAssign(token.LSHIFT, token.LSHIFT_ASSIGN)
+        default:
             tok = newToken(token.LT, l.ch)
         }
     case '>':
-        if l.peekChar() == '=' {
+        switch l.peekChar() {
+        case '=':
             ch := l.ch
             l.nextChar()
             tok = token.Token{Type: token.GREATER_EQ, Literal: string(ch) + string(l.ch)}
-        } else {
+        case '>':
+            l.nextChar()
+            tok = l.withAssign(token.RSHIFT, token.RSHIFT_ASSIGN)
+        default:
             tok = newToken(token.GT, l.ch)
         }
     case '{':
         tok = newToken(token.LSQB, l.ch)
+        l.parens += 1
     case '}':
         tok = newToken(token.RSQB, l.ch)
+        l.closeParen()
     case '(':
         tok = newToken(token.LPAR, l.ch)
+        l.parens += 1
     case ')':
         tok = newToken(token.RPAR, l.ch)
+        l.closeParen()
     case '[':
         tok = newToken(token.LBR, l.ch)
+        l.parens += 1
     case ']':
         tok = newToken(token.RBR, l.ch)
+        l.closeParen()
     case ',':
         tok = newToken(token.COMMA, l.ch)
+    case '.':
+        if isDigit(l.peekChar()) {
+            tok = l.readNumber(pos)
+            tok.Pos = pos
+            l.pending = append(l.pending, tok)
+
+            return
+        }
+
+        tok = newToken(token.DOT, l.ch)
     case ':':
         tok = newToken(token.COLON, l.ch)
-    case '"':
-        tok.Type = token.STRING
-        tok.Literal = l.readString()
+    case '"', '\'':
+        tok = l.readString("", pos)
+        tok.Pos = pos
+        l.pending = append(l.pending, tok)
+
+        return
     case 0:
-        tok.Literal = ""
-        tok.Type = token.EOF
+        l.readEOF(pos)
+        return
     default:
         if isLetter(l.ch) {
             tok.Literal = l.readIdent()
             tok.Type = token.LookupKey(tok.Literal)
 
-            return tok
-        } else if isDigit(l.ch) {
-            literal, flag := l.readNumber()
-            tok.Literal = literal
-            if flag {
-                tok.Type = token.FLOAT
-            } else {
-                tok.Type = token.INT
+            if isStringPrefix(tok.Literal) && (l.ch == '"' || l.ch == '\'') {
+                tok = l.readString(tok.Literal, pos)
             }
-            
-            return tok
+
+            tok.Pos = pos
+            l.pending = append(l.pending, tok)
+
+            return
+        } else if isDigit(l.ch) {
+            tok = l.readNumber(pos)
+            tok.Pos = pos
+            l.pending = append(l.pending, tok)
+
+            return
         } else {
-            panic(
-                fmt.Sprintf(
-                    "implement illegal chars, char %q caused error",
-                    string(l.ch),
-                ),
-            )
+            tok = l.illegalChar(pos)
         }
     }
 
+    tok.Pos = pos
+    l.pending = append(l.pending, tok)
+
     l.nextChar()
-    return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+// readIndentation measures the indentation of a new line and queues the
+// INDENT or DEDENT tokens needed to get from the enclosing block to it.
+// Blank lines are skipped entirely and don't affect indentation.
+func (l *Lexer) readIndentation() {
+    var col, alt int
+
+    for {
+        col, alt = 0, 0
+
+        for l.ch == ' ' || l.ch == '\t' || l.ch == '\f' || l.ch == '\r' {
+            switch l.ch {
+            case ' ':
+                col += 1
+                alt += 1
+            case '\t':
+                col = (col / tabSize + 1) * tabSize
+                alt += 1
+            case '\f':
+                col, alt = 0, 0
+            }
+            l.nextChar()
+        }
+
+        // Lines holding only a comment are blank too.
+        if l.ch == '#' {
+            l.skipComment()
+        }
+
+        if l.ch != '\n' {
+            break
+        }
+
+        l.nextChar()
+    }
+
+    if l.ch == 0 {
+        return
+    }
+
+    pos := l.pos()
+    top := l.indents[len(l.indents) - 1]
+
+    switch {
+    case col == top.col:
+        if alt != top.alt {
+            l.tabError(pos)
+        }
+    case col > top.col:
+        if alt <= top.alt {
+            l.tabError(pos)
+        }
+
+        l.indents = append(l.indents, indent{col: col, alt: alt})
+        l.pending = append(
+            l.pending, token.Token{Type: token.INDENT, Pos: pos})
+    default:
+        for len(l.indents) > 1 && col < top.col {
+            l.indents = l.indents[:len(l.indents) - 1]
+            top = l.indents[len(l.indents) - 1]
+            l.pending = append(
+                l.pending, token.Token{Type: token.DEDENT, Pos: pos})
+        }
+
+        if col != top.col {
+            l.errorAt(
+                pos,
+                "IndentationError: unindent does not match any outer indentation level",
+            )
+        } else if alt != top.alt {
+            l.tabError(pos)
+        }
+    }
+}
+
+// readEOF queues the end of input, terminating the last logical line and
+// closing every block that is still open.
+func (l *Lexer) readEOF(pos token.Position) {
+    if l.prevType != "" && l.prevType != token.NEWL && l.prevType != token.EOF {
+        l.pending = append(l.pending, token.Token{Type: token.NEWL, Pos: pos})
+    }
+
+    for len(l.indents) > 1 {
+        l.indents = l.indents[:len(l.indents) - 1]
+        l.pending = append(l.pending, token.Token{Type: token.DEDENT, Pos: pos})
+    }
+
+    l.pending = append(l.pending, token.Token{Type: token.EOF, Pos: pos})
+}
+
+func (l *Lexer) tabError(pos token.Position) {
+    l.errorAt(
+        pos,
+        "TabError: inconsistent use of tabs and spaces in indentation",
+    )
+}
+
+func (l *Lexer) errorAt(pos token.Position, msg string) {
+    l.errors = append(l.errors, NewSyntaxError(pos, msg))
+}
+
+// pos returns the position of the current character.
+func (l *Lexer) pos() token.Position {
+    return token.Position{
+        Filename: l.filename,
+        Offset: l.offset + l.position,
+        Line: l.line,
+        Column: l.column,
+    }
+}
+
+func newToken(tokenType token.TokenType, ch rune) token.Token {
     return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
 func (l *Lexer) readIdent() string {
     position := l.position
-    for isLetter(l.ch) {
+    for isLetter(l.ch) || isDigit(l.ch) || isIdentContinue(l.ch) {
         l.nextChar()
     }
 
     return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() (string, bool) {
-    var flag bool
-    position := l.position
+// radixes are the prefixes of non-decimal integer literals.
+var radixes = map[rune]struct{
+    name string
+    isDigit func(rune) bool
+}{
+    'x': {"hexadecimal", isHexDigit},
+    'o': {"octal", func(ch rune) bool { return ch >= '0' && ch <= '7' }},
+    'b': {"binary", func(ch rune) bool { return ch == '0' || ch == '1' }},
+}
 
-    for {
-        if isDigit(l.ch) {
+// readNumber lexes a numeric literal: a decimal, hexadecimal, octal or
+// binary int, a float with a fraction and/or an exponent, or an imaginary
+// literal ending in "j". Digits can be separated by single underscores.
+// Malformed literals are reported at pos and lexed as ILLEGAL.
+func (l *Lexer) readNumber(pos token.Position) token.Token {
+    start := l.position
+    tokType := token.TokenType(token.INT)
+    name := "decimal"
+    valid := true
+
+    if radix, ok := radixes[unicode.ToLower(l.peekChar())]; ok && l.ch == '0' {
+        name = radix.name
+        l.nextChar()
+        l.nextChar()
+
+        // An underscore may follow the prefix.
+        if l.ch == '_' {
             l.nextChar()
-        } else if l.ch == '.' {
+        }
+
+        valid = l.readDigits(radix.isDigit)
+    } else {
+        if l.ch != '.' {
+            valid = l.readDigits(isDigit)
+        }
+
+        if valid && l.ch == '.' {
+            tokType = token.FLOAT
             l.nextChar()
-            if flag {
-                break
+
+            if isDigit(l.ch) {
+                valid = l.readDigits(isDigit)
             }
+        }
 
-            flag = true
-        } else {
-            break
+        if valid && (l.ch == 'e' || l.ch == 'E') {
+            tokType = token.FLOAT
+            l.nextChar()
+
+            if l.ch == '+' || l.ch == '-' {
+                l.nextChar()
+            }
+
+            valid = l.readDigits(isDigit)
+        }
+
+        if valid && (l.ch == 'j' || l.ch == 'J') {
+            tokType = token.IMAG
+            l.nextChar()
         }
     }
 
-    return l.input[position:l.position], flag
+    // A literal runs into a following name or digit, as in "1abc" or
+    // "0b12".
+    for isLetter(l.ch) || isDigit(l.ch) {
+        valid = false
+        l.nextChar()
+    }
+
+    literal := l.input[start:l.position]
+    digits := strings.ReplaceAll(literal, "_", "")
+
+    switch {
+    case !valid:
+        l.errorAt(pos, fmt.Sprintf("SyntaxError: invalid %s literal", name))
+        tokType = token.ILLEGAL
+    case tokType == token.INT && name == "decimal" &&
+        digits[0] == '0' && strings.Trim(digits, "0") != "":
+        l.errorAt(
+            pos,
+            "SyntaxError: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers",
+        )
+        tokType = token.ILLEGAL
+    }
+
+    return token.Token{Type: tokType, Literal: literal}
 }
 
-func (l *Lexer) readString() string {
-    position := l.position + 1
+// readDigits reads digits accepted by isValid, single underscores may
+// separate them. It reports false if there are no digits or an underscore
+// isn't followed by a digit.
+func (l *Lexer) readDigits(isValid func(rune) bool) bool {
+    if !isValid(l.ch) {
+        return false
+    }
 
-    for {
+    for isValid(l.ch) {
         l.nextChar()
-        if l.ch == '"' || l.ch == 0 || l.ch == '\n' {
-            break
+
+        if l.ch == '_' {
+            l.nextChar()
+
+            if !isValid(l.ch) {
+                return false
+            }
         }
     }
 
-    return l.input[position:l.position]
+    return true
+}
+
+// illegalChar lexes a character that can't start a token as ILLEGAL.
+func (l *Lexer) illegalChar(pos token.Position) token.Token {
+    switch {
+    case l.ch == '\\':
+        l.errorAt(
+            pos,
+            "SyntaxError: unexpected character after line continuation character",
+        )
+    case l.ch < utf8.RuneSelf && unicode.IsPrint(l.ch):
+        l.errorAt(pos, "SyntaxError: invalid syntax")
+    case unicode.IsPrint(l.ch):
+        l.errorAt(
+            pos,
+            fmt.Sprintf("SyntaxError: invalid character '%c' (U+%04X)", l.ch, l.ch),
+        )
+    default:
+        l.errorAt(
+            pos,
+            fmt.Sprintf("SyntaxError: invalid non-printable character U+%04X", l.ch),
+        )
+    }
+
+    return newToken(token.ILLEGAL, l.ch)
+}
+
+// withAssign returns the operator op, which ends at the current character,
+// or the augmented assignment assign if the operator is followed by '='.
+func (l *Lexer) withAssign(op, assign token.TokenType) token.Token {
+    if l.peekChar() == '=' {
+        l.nextChar()
+        return token.Token{Type: assign, Literal: string(assign)}
+    }
+
+    return token.Token{Type: op, Literal: string(op)}
+}
+
+func (l *Lexer) closeParen() {
+    if l.parens > 0 {
+        l.parens -= 1
+    }
 }
 
-func isLetter(ch byte) bool {
-    return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+// skipComment skips a comment up to the end of the line.
+func (l *Lexer) skipComment() {
+    for l.ch != '\n' && l.ch != 0 {
+        l.nextChar()
+    }
 }
 
-func isDigit(ch byte) bool {
+// readString lexes a string literal starting at its opening quote, after
+// prefix was read. The literal of STRING and BYTES tokens is the value with
+// the escapes decoded, FSTRING tokens keep the source text, prefix and
+// quotes included, for the parser to split into parts.
+func (l *Lexer) readString(prefix string, pos token.Position) token.Token {
+    start := l.position - len(prefix)
+    quote := l.ch
+    closing := string(quote)
+
+    if strings.HasPrefix(l.input[l.position:], strings.Repeat(closing, 3)) {
+        closing = strings.Repeat(closing, 3)
+        l.nextChar()
+        l.nextChar()
+    }
+
+    l.nextChar()
+    bodyStart := l.position
+
+    for !strings.HasPrefix(l.input[l.position:], closing) {
+        if l.ch == 0 || (l.ch == '\n' && len(closing) == 1) {
+            msg := "SyntaxError: unterminated string literal (detected at line %d)"
+            if len(closing) == 3 {
+                msg = "SyntaxError: unterminated triple-quoted string literal (detected at line %d)"
+            }
+
+            l.errorAt(pos, fmt.Sprintf(msg, l.line))
+
+            // The error is reported, the parser skips the ILLEGAL token
+            // without reporting another one.
+            return token.Token{
+                Type: token.ILLEGAL,
+                Literal: l.input[start:l.position],
+            }
+        }
+
+        // An escaped quote or newline never ends the literal, not even in
+        // raw strings.
+        if l.ch == '\\' && l.peekChar() != 0 {
+            l.nextChar()
+        }
+
+        l.nextChar()
+    }
+
+    body := l.input[bodyStart:l.position]
+    for range closing {
+        l.nextChar()
+    }
+
+    prefix = strings.ToLower(prefix)
+
+    switch {
+    case strings.Contains(prefix, "f"):
+        return token.Token{
+            Type: token.FSTRING,
+            Literal: l.input[start:l.position],
+        }
+    case strings.Contains(prefix, "b"):
+        for i := 0; i < len(body); i++ {
+            if body[i] >= 0x80 {
+                l.errorAt(
+                    pos,
+                    "SyntaxError: bytes can only contain ASCII literal characters",
+                )
+                break
+            }
+        }
+
+        if !strings.Contains(prefix, "r") {
+            body = l.decodeEscapes(body, true, pos)
+        }
+
+        return token.Token{Type: token.BYTES, Literal: body}
+    case strings.Contains(prefix, "r"):
+        return token.Token{Type: token.STRING, Literal: body}
+    default:
+        return token.Token{
+            Type: token.STRING,
+            Literal: l.decodeEscapes(body, false, pos),
+        }
+    }
+}
+
+// decodeEscapes decodes the escapes of a literal body, reporting malformed
+// ones at pos.
+func (l *Lexer) decodeEscapes(body string, bytes bool, pos token.Position) string {
+    decoded, err := DecodeEscapes(body, bytes)
+    if err != nil {
+        l.errorAt(pos, "SyntaxError: " + err.Error())
+    }
+
+    return decoded
+}
+
+// isLetter reports whether ch can start an identifier: a letter of any
+// script or an underscore.
+func isLetter(ch rune) bool {
+    if ch < utf8.RuneSelf {
+        return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+    }
+
+    return unicode.In(ch, unicode.Letter, unicode.Nl)
+}
+
+// isIdentContinue reports whether a non-ASCII ch can continue an
+// identifier, beyond letters and digits: combining marks, digits of other
+// scripts and connector punctuation.
+func isIdentContinue(ch rune) bool {
+    return ch >= utf8.RuneSelf && unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+func isHexDigit(ch rune) bool {
+    return isDigit(ch) || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F'
+}
+
+func isDigit(ch rune) bool {
     return ch >= '0' && ch <= '9'
 }
 
+// omitSymbol skips whitespace, comments and backslash line continuations.
+// Inside brackets newlines are skipped too.
 func (l *Lexer) omitSymbol() {
-    for l.ch == ' ' {//|| l.ch == '\r' {
-        l.nextChar()
+    for {
+        switch {
+        case l.ch == ' ' || l.ch == '\t' || l.ch == '\f' || l.ch == '\r':
+            l.nextChar()
+        case l.ch == '\n' && l.parens > 0:
+            l.nextChar()
+        case l.ch == '#':
+            l.skipComment()
+        case l.ch == '\\' && l.continuesLine():
+            for l.ch != '\n' {
+                l.nextChar()
+            }
+
+            l.nextChar()
+        default:
+            return
+        }
     }
 }
 
-func (l *Lexer) peekChar() byte {
+// continuesLine reports whether the backslash at the current character is
+// the last character of its line.
+func (l *Lexer) continuesLine() bool {
+    rest := strings.TrimPrefix(l.input[l.readPosition:], "\r")
+    return strings.HasPrefix(rest, "\n")
+}
+
+func (l *Lexer) peekChar() rune {
     if l.readPosition >= l.inputSize {
         return 0
     } else {
-        return l.input[l.readPosition]
+        ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+        return ch
     }
 }
 
-func (l *Lexer) GetDepth() int {
-    return l.depth
-}
-
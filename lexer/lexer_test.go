@@ -216,7 +216,7 @@ func TestTokens(t *testing.T) {
 
         {token.FOR, "for"},
         {token.NAME, "i"},
-        {token.NAME, "in"},
+        {token.IN, "in"},
         {token.NAME, "range"},
         {token.LPAR, "("},
         {token.NAME, "b"},
@@ -261,7 +261,8 @@ func TestTokens(t *testing.T) {
     for i, tt := range tests {
         tok := l.NextToken()
 
-        for tok.Type == token.NEWL {
+        for tok.Type == token.NEWL ||
+            tok.Type == token.INDENT || tok.Type == token.DEDENT {
             tok = l.NextToken()
         }
 
@@ -277,3 +278,525 @@ func TestTokens(t *testing.T) {
     }
 }
 
+
+func TestTokenPositions(t *testing.T) {
+    input := "a = 35\nbc(a, \"x\")"
+
+    tests := []struct {
+        expectedType token.TokenType
+        expectedLine int
+        expectedColumn int
+        expectedOffset int
+    }{
+        {token.NAME, 1, 1, 0},
+        {token.ASSIGN, 1, 3, 2},
+        {token.INT, 1, 5, 4},
+        {token.NEWL, 1, 7, 6},
+        {token.NAME, 2, 1, 7},
+        {token.LPAR, 2, 3, 9},
+        {token.NAME, 2, 4, 10},
+        {token.COMMA, 2, 5, 11},
+        {token.STRING, 2, 7, 13},
+        {token.RPAR, 2, 10, 16},
+        {token.NEWL, 2, 11, 17},
+        {token.EOF, 2, 11, 17},
+    }
+
+    l := GetFileLexer("test.py", input)
+
+    for i, tt := range tests {
+        tok := l.NextToken()
+
+        if tok.Type != tt.expectedType {
+            t.Fatalf("tests[%d] - wrong token type: expected %q, got %q",
+                i, tt.expectedType, tok.Type)
+        }
+
+        if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+            t.Fatalf("tests[%d] - wrong token position: expected %d:%d, got %d:%d",
+                i, tt.expectedLine, tt.expectedColumn, tok.Pos.Line, tok.Pos.Column)
+        }
+
+        if tok.Pos.Offset != tt.expectedOffset {
+            t.Fatalf("tests[%d] - wrong token offset: expected %d, got %d",
+                i, tt.expectedOffset, tok.Pos.Offset)
+        }
+
+        if tok.Pos.Filename != "test.py" {
+            t.Fatalf("tests[%d] - wrong token filename: expected %q, got %q",
+                i, "test.py", tok.Pos.Filename)
+        }
+    }
+}
+
+func TestIndentation(t *testing.T) {
+    input := `def f(a):
+    if a:
+
+        return 1
+    return 2
+f(1)
+`
+
+    expected := []token.TokenType{
+        token.FDEF, token.NAME, token.LPAR, token.NAME, token.RPAR,
+        token.COLON, token.NEWL,
+        token.INDENT, token.IF, token.NAME, token.COLON, token.NEWL,
+        token.INDENT, token.RETURN, token.INT, token.NEWL,
+        token.DEDENT, token.RETURN, token.INT, token.NEWL,
+        token.DEDENT, token.NAME, token.LPAR, token.INT, token.RPAR,
+        token.NEWL,
+        token.EOF,
+    }
+
+    l := GetLexer(input)
+
+    for i, tt := range expected {
+        tok := l.NextToken()
+
+        if tok.Type != tt {
+            t.Fatalf("tests[%d] - wrong token type: expected %q, got %q",
+                i, tt, tok.Type)
+        }
+    }
+
+    if len(l.Errors()) != 0 {
+        t.Fatalf("unexpected lexer errors: %v", l.Errors())
+    }
+}
+
+func TestIndentationErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {
+            "if a:\n        b\n    c\n",
+            "3:5: IndentationError: unindent does not match any outer indentation level",
+        },
+        {
+            "if a:\n\tif b:\n\t\tc\n        d\n",
+            "4:9: TabError: inconsistent use of tabs and spaces in indentation",
+        },
+    }
+
+    for _, tt := range tests {
+        l := GetLexer(tt.input)
+
+        for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+        }
+
+        if len(l.Errors()) != 1 {
+            t.Fatalf("expected 1 lexer error, got: %v", l.Errors())
+        }
+
+        if l.Errors()[0] != tt.expected {
+            t.Fatalf("expected lexer error: %q, got: %q",
+                tt.expected, l.Errors()[0])
+        }
+    }
+}
+
+func TestOperators(t *testing.T) {
+    input := "a // b % c & d | e ^ ~f << g >> h <= i >= j **= //= %= &= |= ^= <<= >>= **"
+
+    expected := []struct {
+        expectedType token.TokenType
+        expectedLiteral string
+    }{
+        {token.NAME, "a"},
+        {token.DOUBLE_SLASH, "//"},
+        {token.NAME, "b"},
+        {token.PERCENT, "%"},
+        {token.NAME, "c"},
+        {token.AMPERSAND, "&"},
+        {token.NAME, "d"},
+        {token.PIPE, "|"},
+        {token.NAME, "e"},
+        {token.CARET, "^"},
+        {token.TILDE, "~"},
+        {token.NAME, "f"},
+        {token.LSHIFT, "<<"},
+        {token.NAME, "g"},
+        {token.RSHIFT, ">>"},
+        {token.NAME, "h"},
+        {token.LESS_EQ, "<="},
+        {token.NAME, "i"},
+        {token.GREATER_EQ, ">="},
+        {token.NAME, "j"},
+        {token.POW_ASSIGN, "**="},
+        {token.FLOOR_DIV_ASSIGN, "//="},
+        {token.MOD_ASSIGN, "%="},
+        {token.AND_ASSIGN, "&="},
+        {token.OR_ASSIGN, "|="},
+        {token.XOR_ASSIGN, "^="},
+        {token.LSHIFT_ASSIGN, "<<="},
+        {token.RSHIFT_ASSIGN, ">>="},
+        {token.DOUBLE_STAR, "**"},
+    }
+
+    l := GetLexer(input)
+
+    for i, tt := range expected {
+        tok := l.NextToken()
+
+        if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+            t.Fatalf("tests[%d] - expected token %q %q, got %q %q",
+                i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+        }
+    }
+}
+
+func TestStrings(t *testing.T) {
+    input := `'a"b' "it's" "a\"b\\" "\t\x41é\101\n" r"\d\n" b"\x00\xff" ` +
+        `f"{x!r:>{w}}" rb'\x' """multi
+line""" '' "a\
+b"`
+
+    expected := []struct {
+        expectedType token.TokenType
+        expectedLiteral string
+    }{
+        {token.STRING, `a"b`},
+        {token.STRING, "it's"},
+        {token.STRING, `a"b\`},
+        {token.STRING, "\tAéA\n"},
+        {token.STRING, `\d\n`},
+        {token.BYTES, "\x00\xff"},
+        {token.FSTRING, `f"{x!r:>{w}}"`},
+        {token.BYTES, `\x`},
+        {token.STRING, "multi\nline"},
+        {token.STRING, ""},
+        {token.STRING, "ab"},
+        {token.NEWL, ""},
+        {token.EOF, ""},
+    }
+
+    l := GetLexer(input)
+
+    for i, tt := range expected {
+        tok := l.NextToken()
+
+        if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+            t.Fatalf("tests[%d] - expected token %q %q, got %q %q",
+                i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+        }
+    }
+
+    if len(l.Errors()) != 0 {
+        t.Fatalf("expected no lexer errors, got: %v", l.Errors())
+    }
+}
+
+func TestStringErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {
+            "x = 'abc\n",
+            "1:5: SyntaxError: unterminated string literal (detected at line 1)",
+        },
+        {
+            "x = \"\"\"abc\n\n",
+            "1:5: SyntaxError: unterminated triple-quoted string literal (detected at line 3)",
+        },
+        {
+            `"\x4"`,
+            `1:1: SyntaxError: (unicode error) 'unicodeescape' codec can't decode bytes in position 0-2: truncated \xXX escape`,
+        },
+        {
+            `"\N{BULLET}"`,
+            `1:1: SyntaxError: (unicode error) 'unicodeescape' codec can't decode bytes in position 0-9: \N{...} escapes are not supported`,
+        },
+        {
+            `"a\N"`,
+            `1:1: SyntaxError: (unicode error) 'unicodeescape' codec can't decode bytes in position 1-2: malformed \N character escape`,
+        },
+        {
+            `"\N{abc"`,
+            `1:1: SyntaxError: (unicode error) 'unicodeescape' codec can't decode bytes in position 0-5: malformed \N character escape`,
+        },
+        {
+            `b"\x4"`,
+            `1:1: SyntaxError: (value error) invalid \x escape at position 0`,
+        },
+        {
+            `b"é"`,
+            "1:1: SyntaxError: bytes can only contain ASCII literal characters",
+        },
+    }
+
+    for _, tt := range tests {
+        l := GetLexer(tt.input)
+
+        for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+        }
+
+        if len(l.Errors()) != 1 {
+            t.Fatalf("expected 1 lexer error, got: %v", l.Errors())
+        }
+
+        if l.Errors()[0] != tt.expected {
+            t.Fatalf("expected lexer error: %q, got: %q",
+                tt.expected, l.Errors()[0])
+        }
+    }
+}
+
+func TestIdentifiers(t *testing.T) {
+    input := "add2 = größe + π_1 + _x9y\n\"é\" 名前"
+
+    expected := []struct {
+        expectedType token.TokenType
+        expectedLiteral string
+        line int
+        column int
+    }{
+        {token.NAME, "add2", 1, 1},
+        {token.ASSIGN, "=", 1, 6},
+        {token.NAME, "größe", 1, 8},
+        {token.PLUS, "+", 1, 14},
+        {token.NAME, "π_1", 1, 16},
+        {token.PLUS, "+", 1, 20},
+        {token.NAME, "_x9y", 1, 22},
+        {token.NEWL, "\n", 1, 26},
+        {token.STRING, "é", 2, 1},
+        {token.NAME, "名前", 2, 5},
+    }
+
+    l := GetLexer(input)
+
+    for i, tt := range expected {
+        tok := l.NextToken()
+
+        if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+            t.Fatalf("tests[%d] - expected token %q %q, got %q %q",
+                i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+        }
+
+        if tok.Pos.Line != tt.line || tok.Pos.Column != tt.column {
+            t.Fatalf("tests[%d] - expected position %d:%d, got %d:%d",
+                i, tt.line, tt.column, tok.Pos.Line, tok.Pos.Column)
+        }
+    }
+}
+
+func TestByteOrderMark(t *testing.T) {
+    l := GetFileLexer("test.py", "\uFEFFx = 1\n")
+
+    tok := l.NextToken()
+    if tok.Type != token.NAME || tok.Literal != "x" {
+        t.Fatalf("expected token NAME \"x\", got %q %q", tok.Type, tok.Literal)
+    }
+
+    if tok.Pos.Line != 1 || tok.Pos.Column != 1 || tok.Pos.Offset != len("\uFEFF") {
+        t.Fatalf("expected position 1:1 at offset 3, got: %+v", tok.Pos)
+    }
+
+    for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+    }
+
+    if len(l.Errors()) != 0 {
+        t.Fatalf("expected no lexer errors, got: %v", l.Errors())
+    }
+}
+
+func TestCommentsAndContinuations(t *testing.T) {
+    input := `# leading comment
+x = [1,  # inside brackets
+    2] + \
+    3
+    # indented comment
+if x:  # trailing
+    y = 1
+`
+
+    expected := []struct {
+        expectedType token.TokenType
+        expectedLiteral string
+    }{
+        {token.NAME, "x"},
+        {token.ASSIGN, "="},
+        {token.LBR, "["},
+        {token.INT, "1"},
+        {token.COMMA, ","},
+        {token.INT, "2"},
+        {token.RBR, "]"},
+        {token.PLUS, "+"},
+        {token.INT, "3"},
+        {token.NEWL, "\n"},
+        {token.IF, "if"},
+        {token.NAME, "x"},
+        {token.COLON, ":"},
+        {token.NEWL, "\n"},
+        {token.INDENT, ""},
+        {token.NAME, "y"},
+        {token.ASSIGN, "="},
+        {token.INT, "1"},
+        {token.NEWL, "\n"},
+        {token.DEDENT, ""},
+        {token.EOF, ""},
+    }
+
+    l := GetLexer(input)
+
+    for i, tt := range expected {
+        tok := l.NextToken()
+
+        if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+            t.Fatalf("tests[%d] - expected token %q %q, got %q %q",
+                i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+        }
+    }
+
+    if len(l.Errors()) != 0 {
+        t.Fatalf("expected no lexer errors, got: %v", l.Errors())
+    }
+}
+
+func TestNumbers(t *testing.T) {
+    input := "0xFF 0o17 0b1010 0x_ff 1_000 0 00 1.5 .5 1. 1e-9 2.5E+3 1_0.0_1 3j 1.5J 1e3j 1.2.real"
+
+    expected := []struct {
+        expectedType token.TokenType
+        expectedLiteral string
+    }{
+        {token.INT, "0xFF"},
+        {token.INT, "0o17"},
+        {token.INT, "0b1010"},
+        {token.INT, "0x_ff"},
+        {token.INT, "1_000"},
+        {token.INT, "0"},
+        {token.INT, "00"},
+        {token.FLOAT, "1.5"},
+        {token.FLOAT, ".5"},
+        {token.FLOAT, "1."},
+        {token.FLOAT, "1e-9"},
+        {token.FLOAT, "2.5E+3"},
+        {token.FLOAT, "1_0.0_1"},
+        {token.IMAG, "3j"},
+        {token.IMAG, "1.5J"},
+        {token.IMAG, "1e3j"},
+        {token.FLOAT, "1.2"},
+        {token.DOT, "."},
+        {token.NAME, "real"},
+        {token.NEWL, ""},
+        {token.EOF, ""},
+    }
+
+    l := GetLexer(input)
+
+    for i, tt := range expected {
+        tok := l.NextToken()
+
+        if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+            t.Fatalf("tests[%d] - expected token %q %q, got %q %q",
+                i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+        }
+    }
+
+    if len(l.Errors()) != 0 {
+        t.Fatalf("expected no lexer errors, got: %v", l.Errors())
+    }
+}
+
+func TestIllegalTokens(t *testing.T) {
+    tests := []struct {
+        input string
+        literal string
+        expected string
+    }{
+        {"x = 1 $ 2", "$", "1:7: SyntaxError: invalid syntax"},
+        {"x = a ? b", "?", "1:7: SyntaxError: invalid syntax"},
+        {"x = 1 € 2", "€", "1:7: SyntaxError: invalid character '€' (U+20AC)"},
+        {
+            "x = 1 \\ 2",
+            "\\",
+            "1:7: SyntaxError: unexpected character after line continuation character",
+        },
+        {"x = 0x", "0x", "1:5: SyntaxError: invalid hexadecimal literal"},
+        {"x = 0b102", "0b102", "1:5: SyntaxError: invalid binary literal"},
+        {"x = 0o8", "0o8", "1:5: SyntaxError: invalid octal literal"},
+        {"x = 1__0", "1__0", "1:5: SyntaxError: invalid decimal literal"},
+        {"x = 1_", "1_", "1:5: SyntaxError: invalid decimal literal"},
+        {"x = 1e", "1e", "1:5: SyntaxError: invalid decimal literal"},
+        {"x = 1abc", "1abc", "1:5: SyntaxError: invalid decimal literal"},
+        {
+            "x = 012",
+            "012",
+            "1:5: SyntaxError: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers",
+        },
+    }
+
+    for _, tt := range tests {
+        l := GetLexer(tt.input)
+
+        var illegal *token.Token
+        for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+            if tok.Type == token.ILLEGAL {
+                found := tok
+                illegal = &found
+            }
+        }
+
+        if illegal == nil || illegal.Literal != tt.literal {
+            t.Fatalf("expected ILLEGAL token %q in %q, got: %v",
+                tt.literal, tt.input, illegal)
+        }
+
+        if len(l.Errors()) != 1 {
+            t.Fatalf("expected 1 lexer error, got: %v", l.Errors())
+        }
+
+        if l.Errors()[0] != tt.expected {
+            t.Fatalf("expected lexer error: %q, got: %q",
+                tt.expected, l.Errors()[0])
+        }
+    }
+}
+
+func TestSyntaxErrorExcerpt(t *testing.T) {
+    tests := []struct {
+        filename string
+        input string
+        expected string
+    }{
+        {
+            "test.py",
+            "x = 1\nif x:\n    y = 2 $ 3\n",
+            "  File \"test.py\", line 3\n    y = 2 $ 3\n          ^\nSyntaxError: invalid syntax",
+        },
+        {
+            "",
+            "x = 'é' ?",
+            "    x = 'é' ?\n            ^\nSyntaxError: invalid syntax",
+        },
+        {
+            "test.py",
+            "if x:\n\tif y:\n        pass\n",
+            "  File \"test.py\", line 3\n    pass\n    ^\nTabError: inconsistent use of tabs and spaces in indentation",
+        },
+        {
+            "test.py",
+            "\uFEFFx = 1 ?",
+            "  File \"test.py\", line 1\n    x = 1 ?\n          ^\nSyntaxError: invalid syntax",
+        },
+    }
+
+    for _, tt := range tests {
+        l := GetFileLexer(tt.filename, tt.input)
+
+        for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+        }
+
+        if len(l.SyntaxErrors()) != 1 {
+            t.Fatalf("expected 1 lexer error, got: %v", l.Errors())
+        }
+
+        excerpt := l.SyntaxErrors()[0].FormatExcerpt(tt.input)
+        if excerpt != tt.expected {
+            t.Errorf("expected excerpt:\n%s\ngot:\n%s", tt.expected, excerpt)
+        }
+    }
+}
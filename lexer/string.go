@@ -0,0 +1,154 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringPrefixes are the prefixes a string literal can have, in any case.
+var stringPrefixes = map[string]bool{
+    "r": true,
+    "u": true,
+    "b": true,
+    "br": true,
+    "rb": true,
+    "f": true,
+    "fr": true,
+    "rf": true,
+}
+
+func isStringPrefix(ident string) bool {
+    return stringPrefixes[strings.ToLower(ident)]
+}
+
+// simpleEscapes maps the character after a backslash to what it stands for.
+var simpleEscapes = map[byte]byte{
+    '\\': '\\',
+    '\'': '\'',
+    '"': '"',
+    'a': '\a',
+    'b': '\b',
+    'f': '\f',
+    'n': '\n',
+    'r': '\r',
+    't': '\t',
+    'v': '\v',
+}
+
+// DecodeEscapes decodes the backslash escapes of the body of a string
+// literal, or of a bytes literal if bytes is set. Bytes literals don't
+// have \u, \U and \N escapes and their \x and octal escapes stand for
+// bytes rather than code points. Unknown escapes are kept as they are.
+func DecodeEscapes(body string, bytes bool) (string, error) {
+    if !strings.Contains(body, "\\") {
+        return body, nil
+    }
+
+    var out strings.Builder
+
+    for i := 0; i < len(body); i++ {
+        if body[i] != '\\' || i + 1 == len(body) {
+            out.WriteByte(body[i])
+            continue
+        }
+
+        start := i
+        i += 1
+        ch := body[i]
+
+        if decoded, ok := simpleEscapes[ch]; ok {
+            out.WriteByte(decoded)
+            continue
+        }
+
+        switch {
+        case ch == '\n':
+            // A backslash at the end of a line joins it with the next one.
+        case ch >= '0' && ch <= '7':
+            end := i + 1
+            for end < len(body) && end < i + 3 && body[end] >= '0' && body[end] <= '7' {
+                end += 1
+            }
+
+            value, _ := strconv.ParseUint(body[i:end], 8, 32)
+            writeCodePoint(&out, rune(value), bytes)
+            i = end - 1
+        case ch == 'x' || (!bytes && (ch == 'u' || ch == 'U')):
+            digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[ch]
+
+            end := i + 1 + digits
+            if end > len(body) {
+                end = len(body)
+            }
+
+            value, err := strconv.ParseUint(body[i + 1:end], 16, 32)
+            if err != nil || end - i - 1 != digits {
+                if bytes {
+                    return out.String(), fmt.Errorf(
+                        "(value error) invalid \\x escape at position %d",
+                        start,
+                    )
+                }
+
+                return out.String(), fmt.Errorf(
+                    "(unicode error) 'unicodeescape' codec can't decode bytes in position %d-%d: truncated \\%c%s escape",
+                    start,
+                    end - 1,
+                    ch,
+                    strings.Repeat("X", digits),
+                )
+            }
+
+            if value > utf8.MaxRune {
+                return out.String(), fmt.Errorf(
+                    "(unicode error) 'unicodeescape' codec can't decode bytes in position %d-%d: illegal Unicode character",
+                    start,
+                    end - 1,
+                )
+            }
+
+            writeCodePoint(&out, rune(value), bytes)
+            i = end - 1
+        case ch == 'N' && !bytes:
+            // There is no table of character names to look NAME up in, so
+            // \N{NAME} escapes are rejected rather than kept as they are.
+            end := strings.IndexByte(body[i:], '}')
+            if !strings.HasPrefix(body[i + 1:], "{") || end == -1 {
+                end = i
+                if strings.HasPrefix(body[i + 1:], "{") {
+                    end = len(body) - 1
+                }
+
+                return out.String(), fmt.Errorf(
+                    "(unicode error) 'unicodeescape' codec can't decode bytes in position %d-%d: malformed \\N character escape",
+                    start,
+                    end,
+                )
+            }
+
+            return out.String(), fmt.Errorf(
+                "(unicode error) 'unicodeescape' codec can't decode bytes in position %d-%d: \\N{...} escapes are not supported",
+                start,
+                i + end,
+            )
+        default:
+            out.WriteByte('\\')
+            out.WriteByte(ch)
+        }
+    }
+
+    return out.String(), nil
+}
+
+// writeCodePoint writes the character with the code point value, or the
+// byte with that value in a bytes literal.
+func writeCodePoint(out *strings.Builder, value rune, bytes bool) {
+    if bytes {
+        out.WriteByte(byte(value))
+        return
+    }
+
+    out.WriteRune(value)
+}
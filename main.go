@@ -1,16 +1,42 @@
 package main
 
 import (
+    "flag"
     "fmt"
+    "io"
     "os"
     "os/user"
 
+    "mxshs/pyinterpreter/eval"
+    "mxshs/pyinterpreter/lexer"
+    "mxshs/pyinterpreter/object"
+    "mxshs/pyinterpreter/parser"
     "mxshs/pyinterpreter/repl"
-    //"mxshs/pyinterpreter/parser"
 )
 
 func main() {
-//    parser.Run()
+    code := flag.String("c", "", "program passed in as string")
+    flag.Usage = func() {
+        fmt.Fprintf(os.Stderr, "usage: %s [-c cmd | file.py]\n", os.Args[0])
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    switch {
+    case *code != "":
+        os.Exit(run("<string>", *code, os.Stderr))
+    case flag.NArg() > 0:
+        path := flag.Arg(0)
+
+        src, err := os.ReadFile(path)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "can't open file %q: %s\n", path, err)
+            os.Exit(2)
+        }
+
+        os.Exit(run(path, string(src), os.Stderr))
+    }
+
     user, err := user.Current()
     if err != nil {
         panic(err)
@@ -20,3 +46,27 @@ func main() {
     repl.StartREPL(os.Stdin, os.Stdout)
 }
 
+// run evaluates the whole source in a fresh environment and returns the
+// process exit code. Syntax errors are reported to errOut with the source
+// line they were found in, runtime errors with their traceback.
+func run(filename, src string, errOut io.Writer) int {
+    l := lexer.GetFileLexer(filename, src)
+    p := parser.GetParser(l)
+    program := p.ParseProgram()
+
+    if errors := p.SyntaxErrors(); len(errors) != 0 {
+        for _, err := range errors {
+            io.WriteString(errOut, err.FormatExcerpt(src) + "\n")
+        }
+        return 1
+    }
+
+    evaluated := eval.Eval(program, object.NewEnv())
+
+    if err, ok := evaluated.(*object.Error); ok {
+        io.WriteString(errOut, err.FormatTraceback() + "\n")
+        return 1
+    }
+
+    return 0
+}
@@ -0,0 +1,144 @@
+package object
+
+const (
+    CLASS_OBJ = "CLASS"
+    INSTANCE_OBJ = "INSTANCE"
+    BOUND_METHOD_OBJ = "BOUND_METHOD"
+    SUPER_OBJ = "SUPER"
+    NOT_IMPLEMENTED_OBJ = "NOT_IMPLEMENTED"
+)
+
+// Class is a user defined class. Attrs holds the names bound by the class
+// body, e.g. its methods, Base is nil unless the class derives from
+// another one.
+type Class struct {
+    Name string
+    Base *Class
+    Attrs map[string]Object
+}
+
+func (c *Class) Type() ObjectType {
+    return CLASS_OBJ
+}
+
+func (c *Class) Inspect() string {
+    return "<class '" + c.Name + "'>"
+}
+
+// Lookup finds the attribute name in the class or in its bases.
+func (c *Class) Lookup(name string) (Object, bool) {
+    return Lookup(c, name)
+}
+
+// IsSubclass reports whether c is other or derives from it.
+func (c *Class) IsSubclass(other *Class) bool {
+    return IsSubclass(c, other)
+}
+
+func (c *Class) ClassName() string {
+    return c.Name
+}
+
+func (c *Class) Namespace() map[string]Object {
+    return c.Attrs
+}
+
+func (c *Class) Parent() ClassObject {
+    if c.Base == nil {
+        return nil
+    }
+
+    return c.Base
+}
+
+// ClassObject is a user defined class or an exception class, both look up
+// attributes along their chain of bases.
+type ClassObject interface {
+    Object
+    ClassName() string
+    // Namespace holds the names bound by the class body.
+    Namespace() map[string]Object
+    // Parent is the base class, nil if there is none.
+    Parent() ClassObject
+}
+
+// Lookup finds the attribute name in class or in its bases.
+func Lookup(class ClassObject, name string) (Object, bool) {
+    for ; class != nil; class = class.Parent() {
+        if attr, ok := class.Namespace()[name]; ok {
+            return attr, true
+        }
+    }
+
+    return nil, false
+}
+
+// IsSubclass reports whether class is other or derives from it.
+func IsSubclass(class, other ClassObject) bool {
+    for ; class != nil; class = class.Parent() {
+        if class == other {
+            return true
+        }
+    }
+
+    return false
+}
+
+type Instance struct {
+    Class *Class
+    Attrs map[string]Object
+}
+
+func NewInstance(class *Class) *Instance {
+    return &Instance{Class: class, Attrs: map[string]Object{}}
+}
+
+func (i *Instance) Type() ObjectType {
+    return INSTANCE_OBJ
+}
+
+func (i *Instance) Inspect() string {
+    return "<" + i.Class.Name + " object>"
+}
+
+// BoundMethod is a function looked up on an instance, calling it passes
+// Self as the first argument.
+type BoundMethod struct {
+    Self Object
+    Function *Function
+}
+
+func (bm *BoundMethod) Type() ObjectType {
+    return BOUND_METHOD_OBJ
+}
+
+func (bm *BoundMethod) Inspect() string {
+    return "<bound method " + bm.Function.Name + " of " + bm.Self.Inspect() + ">"
+}
+
+// Super delegates attribute lookups on Self to the bases of Class, as
+// returned by super().
+type Super struct {
+    Class ClassObject
+    Self Object
+}
+
+func (s *Super) Type() ObjectType {
+    return SUPER_OBJ
+}
+
+func (s *Super) Inspect() string {
+    return "<super: " + s.Class.Inspect() + ", " + s.Self.Inspect() + ">"
+}
+
+// NotImplemented is returned by binary dunder methods that don't support
+// the other operand, so that the reflected method is tried instead.
+type NotImplemented struct{}
+
+func (ni *NotImplemented) Type() ObjectType {
+    return NOT_IMPLEMENTED_OBJ
+}
+
+func (ni *NotImplemented) Inspect() string {
+    return "NotImplemented"
+}
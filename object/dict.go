@@ -0,0 +1,204 @@
+package object
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+    DICT_OBJ = "DICT"
+)
+
+// HashKey is the hash of a dictionary key. Keys that compare equal have
+// equal hash keys, e.g. 1, 1.0 and true all share the integer hash key 1,
+// but unequal keys can share one too.
+type HashKey struct {
+    Type ObjectType
+    Value uint64
+}
+
+// Hashable is implemented by immutable objects that can be dictionary keys.
+type Hashable interface {
+    Object
+    HashKey() HashKey
+}
+
+// hashModulus is the Mersenne prime 2**61 - 1. Integers hash to their
+// value modulo it like in CPython, whatever their size or type.
+const hashModulus = 1 << 61 - 1
+
+func (i *Integer) HashKey() HashKey {
+    if i.Big != nil {
+        return bigHashKey(i.Big)
+    }
+
+    return intHashKey(i.Value)
+}
+
+func intHashKey(value int64) HashKey {
+    return HashKey{Type: INTEGER_OBJ, Value: uint64(value % hashModulus)}
+}
+
+// bigHashKey hashes an integer that doesn't fit in an int64.
+func bigHashKey(value *big.Int) HashKey {
+    rem := new(big.Int).Rem(value, big.NewInt(hashModulus))
+    return intHashKey(rem.Int64())
+}
+
+func (f *Float) HashKey() HashKey {
+    if f.Value == math.Trunc(f.Value) && math.Abs(f.Value) < 1 << 63 {
+        return intHashKey(int64(f.Value))
+    }
+
+    // Integral floats beyond int64 hash like the big ints equal to them.
+    if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+        value, _ := big.NewFloat(f.Value).Int(nil)
+        return bigHashKey(value)
+    }
+
+    return HashKey{Type: FLOAT_OBJ, Value: math.Float64bits(f.Value)}
+}
+
+// HashKey of a complex number with no imaginary part is the one of its real
+// part, so 1+0j and 1 are the same key.
+func (c *Complex) HashKey() HashKey {
+    if imag(c.Value) == 0 {
+        return (&Float{Value: real(c.Value)}).HashKey()
+    }
+
+    return HashKey{
+        Type: COMPLEX_OBJ,
+        Value: math.Float64bits(real(c.Value)) ^ math.Float64bits(imag(c.Value)) * 1000003,
+    }
+}
+
+func (b *Boolean) HashKey() HashKey {
+    if b.Value {
+        return intHashKey(1)
+    }
+
+    return intHashKey(0)
+}
+
+func (s *String) HashKey() HashKey {
+    h := fnv.New64a()
+    h.Write([]byte(s.Value))
+
+    return HashKey{Type: STRING_OBJ, Value: h.Sum64()}
+}
+
+func (b *Bytes) HashKey() HashKey {
+    h := fnv.New64a()
+    h.Write([]byte(b.Value))
+
+    return HashKey{Type: BYTES_OBJ, Value: h.Sum64()}
+}
+
+// HashKey of a tuple combines the hash keys of its elements, which all have
+// to be Hashable themselves.
+func (t *Tuple) HashKey() HashKey {
+    h := fnv.New64a()
+
+    for _, elem := range t.Elements {
+        key := elem.(Hashable).HashKey()
+        h.Write([]byte(key.Type))
+        binary.Write(h, binary.LittleEndian, key.Value)
+    }
+
+    return HashKey{Type: TUPLE_OBJ, Value: h.Sum64()}
+}
+
+type DictPair struct {
+    Key Object
+    Value Object
+}
+
+// KeysEqual tells apart dictionary keys that share a hash key, the
+// evaluator sets it to its == operator.
+var KeysEqual = func(a, b Object) bool {
+    return false
+}
+
+// Dict is a mapping that keeps its keys in insertion order. Pairs holds
+// the pairs in that order, buckets the indexes of the pairs whose keys
+// share a hash key.
+type Dict struct {
+    Pairs []DictPair
+    buckets map[HashKey][]int
+}
+
+func NewDict() *Dict {
+    return &Dict{buckets: make(map[HashKey][]int)}
+}
+
+func (d *Dict) Type() ObjectType {
+    return DICT_OBJ
+}
+
+func (d *Dict) Inspect() string {
+    var out bytes.Buffer
+
+    out.WriteString("dict({")
+
+    pairs := []string{}
+
+    for _, pair := range d.Pairs {
+        pairs = append(pairs, pair.Key.Inspect() + ": " + pair.Value.Inspect())
+    }
+
+    out.WriteString(strings.Join(pairs, ", ") + "})")
+
+    return out.String()
+}
+
+// find returns the index of the pair whose key is equal to key, or -1.
+func (d *Dict) find(key Hashable) int {
+    for _, i := range d.buckets[key.HashKey()] {
+        if other := d.Pairs[i].Key; other == key || KeysEqual(other, key) {
+            return i
+        }
+    }
+
+    return -1
+}
+
+func (d *Dict) Get(key Hashable) (Object, bool) {
+    i := d.find(key)
+    if i == -1 {
+        return nil, false
+    }
+
+    return d.Pairs[i].Value, true
+}
+
+// Set inserts or updates the value of key. Updating an existing key keeps
+// both its position and the originally inserted key object.
+func (d *Dict) Set(key Hashable, value Object) {
+    if i := d.find(key); i != -1 {
+        d.Pairs[i].Value = value
+        return
+    }
+
+    hash := key.HashKey()
+    d.buckets[hash] = append(d.buckets[hash], len(d.Pairs))
+    d.Pairs = append(d.Pairs, DictPair{Key: key, Value: value})
+}
+
+func (d *Dict) Len() int {
+    return len(d.Pairs)
+}
+
+// Iter iterates over the keys of the dictionary.
+func (d *Dict) Iter() Iterator {
+    keys := []Object{}
+
+    for _, pair := range d.Pairs {
+        keys = append(keys, pair.Key)
+    }
+
+    return &SliceIterator{Items: keys}
+}
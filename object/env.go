@@ -1,11 +1,30 @@
 package object
 
+import "mxshs/pyinterpreter/ast"
+
 func NewNestedEnv(parent *Env) *Env {
     env := NewEnv()
     env.parent = parent
     return env
 }
 
+// NewFunctionEnv returns the env of a function call, scope tells which
+// names are local to the function and which are declared global or
+// nonlocal.
+func NewFunctionEnv(parent *Env, scope *ast.Scope) *Env {
+    env := NewNestedEnv(parent)
+    env.scope = scope
+    return env
+}
+
+// NewClassEnv returns the env a class body runs in. Unlike function envs,
+// it isn't visible to the functions defined in it.
+func NewClassEnv(parent *Env, scope *ast.Scope) *Env {
+    env := NewFunctionEnv(parent, scope)
+    env.class = true
+    return env
+}
+
 func NewEnv() *Env {
     env := make(map[string]Object)
     return &Env{store: env}
@@ -14,34 +33,98 @@ func NewEnv() *Env {
 type Env struct {
     store map[string]Object
     parent *Env
+    scope *ast.Scope
+    class bool
 }
 
+// Get looks name up in the env and then in the enclosing ones. A local of
+// a function is never looked up outside of it, even if it isn't assigned
+// yet.
 func (e *Env) Get(name string) (Object, bool) {
     obj, ok := e.store[name]
-    if !ok && e.parent != nil {
-        obj, ok = e.parent.Get(name)
+    if ok {
+        return obj, true
+    }
+
+    if e.scope != nil {
+        switch {
+        case e.scope.Globals[name]:
+            return e.global().Get(name)
+        case e.scope.Locals[name] && !e.class:
+            return nil, false
+        }
     }
 
-    return obj, ok
+    if e.parent != nil {
+        return e.parent.Get(name)
+    }
+
+    return nil, false
+}
+
+// IsLocal reports whether name is a local of the function the env
+// belongs to.
+func (e *Env) IsLocal(name string) bool {
+    return e.scope != nil && e.scope.Locals[name] && !e.class
+}
+
+// Store returns the names bound in the env itself.
+func (e *Env) Store() map[string]Object {
+    return e.store
 }
 
-func (e *Env) findInGlobalScope(name string) (*Env, bool) {
-    _, ok := e.store[name]
-    if !ok && e.parent != nil {
-        e, ok = e.parent.findInGlobalScope(name)
+// Closure returns the env captured by the functions defined in e, i.e. the
+// nearest env that doesn't belong to a class body.
+func (e *Env) Closure() *Env {
+    for e.class {
+        e = e.parent
     }
 
-    return e, ok
+    return e
 }
 
+// Set binds name in the env, unless it's declared global or nonlocal in
+// which case the module or enclosing function binding is set instead.
 func (e *Env) Set(name string, value Object) Object {
-    parent, ok := e.findInGlobalScope(name)
-    if ok {
-        parent.store[name] = value
-    } else {
-        e.store[name] = value
+    if e.scope != nil {
+        switch {
+        case e.scope.Globals[name]:
+            e.global().store[name] = value
+            return value
+        case e.scope.Nonlocals[name]:
+            if env := e.parent.enclosing(name); env != nil {
+                env.store[name] = value
+                return value
+            }
+        }
     }
 
+    e.store[name] = value
+
     return value
 }
 
+// global returns the module env.
+func (e *Env) global() *Env {
+    for e.parent != nil {
+        e = e.parent
+    }
+
+    return e
+}
+
+// enclosing returns the nearest function env binding name.
+func (e *Env) enclosing(name string) *Env {
+    for ; e != nil && e.scope != nil; e = e.parent {
+        if e.class {
+            continue
+        }
+
+        _, ok := e.store[name]
+        if ok || e.scope.Locals[name] {
+            return e
+        }
+    }
+
+    return nil
+}
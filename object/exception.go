@@ -0,0 +1,106 @@
+package object
+
+const (
+    EXCEPTION_OBJ = "EXCEPTION"
+    EXCEPTION_CLASS_OBJ = "EXCEPTION_CLASS"
+)
+
+// ExceptionClass is a builtin exception type. Classes form a single
+// inheritance hierarchy rooted at BaseException, an except clause naming a
+// class catches the exceptions of every class derived from it.
+type ExceptionClass struct {
+    Name string
+    Base *ExceptionClass
+    // Attrs holds the names bound by the body of a user defined subclass.
+    Attrs map[string]Object
+}
+
+func (ec *ExceptionClass) Type() ObjectType {
+    return EXCEPTION_CLASS_OBJ
+}
+
+func (ec *ExceptionClass) Inspect() string {
+    return "<class '" + ec.Name + "'>"
+}
+
+// IsSubclass reports whether ec is other or derives from it.
+func (ec *ExceptionClass) IsSubclass(other *ExceptionClass) bool {
+    return IsSubclass(ec, other)
+}
+
+func (ec *ExceptionClass) ClassName() string {
+    return ec.Name
+}
+
+func (ec *ExceptionClass) Namespace() map[string]Object {
+    return ec.Attrs
+}
+
+func (ec *ExceptionClass) Parent() ClassObject {
+    if ec.Base == nil {
+        return nil
+    }
+
+    return ec.Base
+}
+
+// ExceptionInstance is the value that is raised and bound by
+// "except ... as name". Attrs holds the attributes set on it, e.g. by the
+// __init__ of a user defined subclass.
+type ExceptionInstance struct {
+    Class *ExceptionClass
+    Message string
+    Attrs map[string]Object
+}
+
+func NewExceptionInstance(class *ExceptionClass, message string) *ExceptionInstance {
+    return &ExceptionInstance{Class: class, Message: message, Attrs: map[string]Object{}}
+}
+
+func (e *ExceptionInstance) Type() ObjectType {
+    return EXCEPTION_OBJ
+}
+
+func (e *ExceptionInstance) Inspect() string {
+    return e.Message
+}
+
+// Lookup finds the attribute name in the class or in its bases.
+func (ec *ExceptionClass) Lookup(name string) (Object, bool) {
+    return Lookup(ec, name)
+}
+
+func newExceptionClass(name string, base *ExceptionClass) *ExceptionClass {
+    class := &ExceptionClass{Name: name, Base: base}
+    ExceptionClasses = append(ExceptionClasses, class)
+
+    return class
+}
+
+// ExceptionClasses lists every builtin exception class.
+var ExceptionClasses []*ExceptionClass
+
+var (
+    BaseException = newExceptionClass("BaseException", nil)
+    Exception = newExceptionClass("Exception", BaseException)
+
+    ArithmeticError = newExceptionClass("ArithmeticError", Exception)
+    ZeroDivisionError = newExceptionClass("ZeroDivisionError", ArithmeticError)
+    OverflowError = newExceptionClass("OverflowError", ArithmeticError)
+
+    LookupError = newExceptionClass("LookupError", Exception)
+    IndexError = newExceptionClass("IndexError", LookupError)
+    KeyError = newExceptionClass("KeyError", LookupError)
+
+    AttributeError = newExceptionClass("AttributeError", Exception)
+    NameError = newExceptionClass("NameError", Exception)
+    UnboundLocalError = newExceptionClass("UnboundLocalError", NameError)
+    TypeError = newExceptionClass("TypeError", Exception)
+    ValueError = newExceptionClass("ValueError", Exception)
+    AssertionError = newExceptionClass("AssertionError", Exception)
+    StopIteration = newExceptionClass("StopIteration", Exception)
+
+    RuntimeError = newExceptionClass("RuntimeError", Exception)
+    NotImplementedError = newExceptionClass("NotImplementedError", RuntimeError)
+    RecursionError = newExceptionClass("RecursionError", RuntimeError)
+)
@@ -0,0 +1,211 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+const (
+    ITERATOR_OBJ = "ITERATOR"
+    RANGE_OBJ = "RANGE"
+)
+
+// Iterable is implemented by every object that can be looped over with for
+// or passed to builtins consuming a sequence of items.
+type Iterable interface {
+    Object
+    Iter() Iterator
+}
+
+// Iterator yields the items of an Iterable one at a time. Next returns the
+// following item and true, or false once the iterator is exhausted. An
+// *Error item aborts the iteration and has to be propagated by the caller.
+type Iterator interface {
+    Iterable
+    Next() (Object, bool)
+}
+
+// Sized is implemented by containers that know the number of their items.
+type Sized interface {
+    Object
+    Len() int
+}
+
+// SliceIterator iterates over a slice of objects, e.g. the items of a list.
+type SliceIterator struct {
+    Items []Object
+    idx int
+}
+
+func (si *SliceIterator) Type() ObjectType {
+    return ITERATOR_OBJ
+}
+
+func (si *SliceIterator) Inspect() string {
+    return "iterator"
+}
+
+func (si *SliceIterator) Iter() Iterator {
+    return si
+}
+
+func (si *SliceIterator) Next() (Object, bool) {
+    if si.idx >= len(si.Items) {
+        return nil, false
+    }
+
+    item := si.Items[si.idx]
+    si.idx += 1
+
+    return item, true
+}
+
+// StringIterator yields the code points of a string, idx is a byte offset.
+type StringIterator struct {
+    Value string
+    idx int
+}
+
+func (si *StringIterator) Type() ObjectType {
+    return ITERATOR_OBJ
+}
+
+func (si *StringIterator) Inspect() string {
+    return "iterator"
+}
+
+func (si *StringIterator) Iter() Iterator {
+    return si
+}
+
+func (si *StringIterator) Next() (Object, bool) {
+    if si.idx >= len(si.Value) {
+        return nil, false
+    }
+
+    _, size := utf8.DecodeRuneInString(si.Value[si.idx:])
+    item := &String{Value: si.Value[si.idx:si.idx + size]}
+    si.idx += size
+
+    return item, true
+}
+
+// BytesIterator yields the bytes of a bytes object as ints.
+type BytesIterator struct {
+    Value string
+    idx int
+}
+
+func (bi *BytesIterator) Type() ObjectType {
+    return ITERATOR_OBJ
+}
+
+func (bi *BytesIterator) Inspect() string {
+    return "iterator"
+}
+
+func (bi *BytesIterator) Iter() Iterator {
+    return bi
+}
+
+func (bi *BytesIterator) Next() (Object, bool) {
+    if bi.idx >= len(bi.Value) {
+        return nil, false
+    }
+
+    item := &Integer{Value: int64(bi.Value[bi.idx])}
+    bi.idx += 1
+
+    return item, true
+}
+
+type Range struct {
+    Start int64
+    Stop int64
+    Step int64
+}
+
+func (r *Range) Type() ObjectType {
+    return RANGE_OBJ
+}
+
+func (r *Range) Inspect() string {
+    if r.Step == 1 {
+        return fmt.Sprintf("range(%d, %d)", r.Start, r.Stop)
+    }
+
+    return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.Stop, r.Step)
+}
+
+func (r *Range) Iter() Iterator {
+    return &RangeIterator{next: r.Start, stop: r.Stop, step: r.Step}
+}
+
+// Len is the number of items, or math.MaxInt64 if there are more, see
+// Length.
+func (r *Range) Len() int {
+    length, ok := r.Length()
+    if !ok {
+        return math.MaxInt64
+    }
+
+    return int(length)
+}
+
+// Length returns the number of items, ok is false if it doesn't fit in an
+// int64, e.g. for range(-2 ** 63, 2 ** 63 - 1).
+func (r *Range) Length() (length int64, ok bool) {
+    // The distance between the bounds always fits in a uint64, and so does
+    // the magnitude of the step.
+    var n uint64
+
+    switch {
+    case r.Step > 0 && r.Start < r.Stop:
+        n = (uint64(r.Stop) - uint64(r.Start) - 1) / uint64(r.Step) + 1
+    case r.Step < 0 && r.Start > r.Stop:
+        n = (uint64(r.Start) - uint64(r.Stop) - 1) / uint64(-r.Step) + 1
+    }
+
+    if n > math.MaxInt64 {
+        return 0, false
+    }
+
+    return int64(n), true
+}
+
+type RangeIterator struct {
+    next int64
+    stop int64
+    step int64
+    // done is set when stepping past the last item overflows next.
+    done bool
+}
+
+func (ri *RangeIterator) Type() ObjectType {
+    return ITERATOR_OBJ
+}
+
+func (ri *RangeIterator) Inspect() string {
+    return "iterator"
+}
+
+func (ri *RangeIterator) Iter() Iterator {
+    return ri
+}
+
+func (ri *RangeIterator) Next() (Object, bool) {
+    if ri.done || ri.step > 0 && ri.next >= ri.stop || ri.step < 0 && ri.next <= ri.stop {
+        return nil, false
+    }
+
+    item := &Integer{Value: ri.next}
+
+    next := ri.next + ri.step
+    if ri.step > 0 && next < ri.next || ri.step < 0 && next > ri.next {
+        ri.done = true
+    }
+    ri.next = next
+
+    return item, true
+}
@@ -3,22 +3,32 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/token"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
     INTEGER_OBJ = "INTEGER"
     FLOAT_OBJ = "FLOAT"
+    COMPLEX_OBJ = "COMPLEX"
     BOOL_OBJ = "BOOLEAN"
     STRING_OBJ = "STIRNG"
     NULL_OBJ = "NULL"
     RETURN_VALUE = "RETURN_VALUE"
+    BREAK_SIGNAL = "BREAK"
+    CONTINUE_SIGNAL = "CONTINUE"
     ERROR_OBJ = "ERROR"
     FUNCTION_OBJ = "FUNCTION"
     BLTIN = "BLTIN_FN"
     LIST = "LIST"
+    TUPLE_OBJ = "TUPLE"
+    SLICE_OBJ = "SLICE"
+    BYTES_OBJ = "BYTES"
 )
 
 type ObjectType string
@@ -28,8 +38,31 @@ type Object interface {
     Inspect() string
 }
 
+// Integer is an int of any size. Big holds the values that don't fit in
+// Value and is nil for all the others, which keeps small ints cheap.
 type Integer struct {
     Value int64
+    Big *big.Int
+}
+
+// NewBigInteger returns the Integer of value, which only keeps value as a
+// big.Int if it doesn't fit in an int64.
+func NewBigInteger(value *big.Int) *Integer {
+    if value.IsInt64() {
+        return &Integer{Value: value.Int64()}
+    }
+
+    return &Integer{Big: value}
+}
+
+// BigValue returns the value of i as a big.Int, which must not be
+// modified.
+func (i *Integer) BigValue() *big.Int {
+    if i.Big != nil {
+        return i.Big
+    }
+
+    return big.NewInt(i.Value)
 }
 
 func (i *Integer) Type() ObjectType {
@@ -37,6 +70,10 @@ func (i *Integer) Type() ObjectType {
 }
 
 func (i *Integer) Inspect() string {
+    if i.Big != nil {
+        return i.Big.String()
+    }
+
     return fmt.Sprintf("%d", i.Value)
 }
 
@@ -48,8 +85,56 @@ func (f *Float) Type() ObjectType {
     return FLOAT_OBJ 
 }
 
+// Inspect shows very large and very small floats with an exponent, like
+// 1e-09 or 1e+16, and integral ones with a fractional part, like 2.0.
 func (f *Float) Inspect() string {
-    return strconv.FormatFloat(f.Value, 'f', -1, 64)
+    str := formatFloat(f.Value)
+    if strings.ContainsAny(str, ".en") {
+        return str
+    }
+
+    return str + ".0"
+}
+
+// formatFloat formats value with the shortest digits that read back as it,
+// e.g. 2 or 1e+16.
+func formatFloat(value float64) string {
+    abs := math.Abs(value)
+
+    switch {
+    case math.IsNaN(value):
+        return "nan"
+    case math.IsInf(value, 1):
+        return "inf"
+    case math.IsInf(value, -1):
+        return "-inf"
+    case abs != 0 && (abs < 1e-4 || abs >= 1e16):
+        return strconv.FormatFloat(value, 'e', -1, 64)
+    default:
+        return strconv.FormatFloat(value, 'f', -1, 64)
+    }
+}
+
+type Complex struct {
+    Value complex128
+}
+
+func (c *Complex) Type() ObjectType {
+    return COMPLEX_OBJ
+}
+
+// Inspect shows complex numbers the way Python does, 2j or (1+2j).
+func (c *Complex) Inspect() string {
+    imag := formatFloat(imag(c.Value)) + "j"
+    if real(c.Value) == 0 && !math.Signbit(real(c.Value)) {
+        return imag
+    }
+
+    if imag[0] != '-' {
+        imag = "+" + imag
+    }
+
+    return "(" + formatFloat(real(c.Value)) + imag + ")"
 }
 
 type Boolean struct {
@@ -76,6 +161,94 @@ func (s *String) Inspect() string {
     return s.Value
 }
 
+func (s *String) Iter() Iterator {
+    return &StringIterator{Value: s.Value}
+}
+
+// Len is the number of code points of s.
+func (s *String) Len() int {
+    return utf8.RuneCountInString(s.Value)
+}
+
+// Repr returns s as a Python string literal, the way repr() shows it.
+func (s *String) Repr() string {
+    return quote(s.Value, false)
+}
+
+// Bytes is an immutable sequence of bytes, held in a string.
+type Bytes struct {
+    Value string
+}
+
+func (b *Bytes) Type() ObjectType {
+    return BYTES_OBJ
+}
+
+func (b *Bytes) Inspect() string {
+    return "b" + quote(b.Value, true)
+}
+
+func (b *Bytes) Iter() Iterator {
+    return &BytesIterator{Value: b.Value}
+}
+
+func (b *Bytes) Len() int {
+    return len(b.Value)
+}
+
+// quote quotes s like Python's repr: in single quotes unless s only
+// contains single quotes, with the unprintable characters escaped. In
+// bytes, every byte out of the printable ASCII range is escaped.
+func quote(s string, bytes bool) string {
+    q := byte('\'')
+    if strings.Contains(s, "'") && !strings.Contains(s, "\"") {
+        q = '"'
+    }
+
+    var out strings.Builder
+    out.WriteByte(q)
+
+    escape := func(r rune) {
+        switch {
+        case r == rune(q) || r == '\\':
+            out.WriteByte('\\')
+            out.WriteRune(r)
+        case r == '\n':
+            out.WriteString("\\n")
+        case r == '\r':
+            out.WriteString("\\r")
+        case r == '\t':
+            out.WriteString("\\t")
+        case r < 0x20 || r == 0x7f || (bytes && r > 0x7f):
+            fmt.Fprintf(&out, "\\x%02x", r)
+        case !bytes && !strconv.IsPrint(r):
+            if r <= 0xff {
+                fmt.Fprintf(&out, "\\x%02x", r)
+            } else if r <= 0xffff {
+                fmt.Fprintf(&out, "\\u%04x", r)
+            } else {
+                fmt.Fprintf(&out, "\\U%08x", r)
+            }
+        default:
+            out.WriteRune(r)
+        }
+    }
+
+    if bytes {
+        for i := 0; i < len(s); i++ {
+            escape(rune(s[i]))
+        }
+    } else {
+        for _, r := range s {
+            escape(r)
+        }
+    }
+
+    out.WriteByte(q)
+
+    return out.String()
+}
+
 type Null struct {
 }
 
@@ -99,8 +272,55 @@ func (rv *ReturnValue) Inspect() string {
     return rv.Value.Inspect()
 }
 
+// Break and Continue are signals unwinding the statements of a loop body
+// up to the loop that handles them, the same way ReturnValue unwinds up to
+// the function call.
+type Break struct {
+}
+
+func (b *Break) Type() ObjectType {
+    return BREAK_SIGNAL
+}
+
+func (b *Break) Inspect() string {
+    return "break"
+}
+
+type Continue struct {
+}
+
+func (c *Continue) Type() ObjectType {
+    return CONTINUE_SIGNAL
+}
+
+func (c *Continue) Inspect() string {
+    return "continue"
+}
+
+// Error is a raised exception propagating through the evaluator until an
+// except clause handles it.
 type Error struct {
+    Class *ExceptionClass
     Message string
+    // Pos is where the error was raised, it is stamped by the evaluator
+    // while the error propagates out of the innermost failing node.
+    Pos token.Position
+    // Traceback holds the call stack at the point the error was raised,
+    // outermost call first.
+    Traceback []*Frame
+    // Value is the instance that was raised, it is nil until one is needed
+    // for errors raised by the evaluator itself.
+    Value *ExceptionInstance
+}
+
+// Frame is a call of a user function on the evaluator's call stack.
+type Frame struct {
+    // Name is the name of the called function, CallPos is the position of
+    // the call expression in the caller.
+    Name string
+    CallPos token.Position
+    Env *Env
+    Function *Function
 }
 
 func (e *Error) Type() ObjectType {
@@ -108,12 +328,92 @@ func (e *Error) Type() ObjectType {
 }
 
 func (e *Error) Inspect() string {
-    return e.Message
+    msg := e.Class.Name
+    if e.Message != "" {
+        msg += ": " + e.Message
+    }
+
+    if e.Pos.IsValid() {
+        return e.Pos.String() + ": " + msg
+    }
+
+    return msg
+}
+
+// FormatTraceback renders the error Python-style: the frames that were
+// active when it was raised, most recent call last, followed by the error.
+func (e *Error) FormatTraceback() string {
+    var out bytes.Buffer
+
+    out.WriteString("Traceback (most recent call last):\n")
+
+    entries := []string{}
+
+    name := "<module>"
+    for _, frame := range e.Traceback {
+        entries = append(entries, formatTracebackEntry(frame.CallPos, name))
+        name = frame.Name
+    }
+    entries = append(entries, formatTracebackEntry(e.Pos, name))
+
+    // Like Python, runs of the same entry (i.e. recursion) are collapsed
+    // after the third repetition.
+    repeated := 0
+    for i, entry := range entries {
+        if i > 0 && entry == entries[i - 1] {
+            repeated += 1
+        } else {
+            writeRepeated(&out, repeated)
+            repeated = 0
+        }
+
+        if repeated < 3 {
+            out.WriteString(entry)
+        }
+    }
+    writeRepeated(&out, repeated)
+
+    out.WriteString(e.Class.Name)
+    if e.Message != "" {
+        out.WriteString(": " + e.Message)
+    }
+
+    return out.String()
+}
+
+func writeRepeated(out *bytes.Buffer, repeated int) {
+    if repeated > 2 {
+        fmt.Fprintf(out, "  [Previous line repeated %d more times]\n", repeated - 2)
+    }
+}
+
+func formatTracebackEntry(pos token.Position, name string) string {
+    filename := pos.Filename
+    if filename == "" {
+        filename = "<unknown>"
+    }
+
+    return fmt.Sprintf("  File \"%s\", line %d, in %s\n", filename, pos.Line, name)
+}
+
+// Exception returns the raised value as bound by "except ... as name".
+func (e *Error) Exception() *ExceptionInstance {
+    if e.Value == nil {
+        e.Value = NewExceptionInstance(e.Class, e.Message)
+    }
+
+    return e.Value
 }
 
 type Function struct {
-    Arguments []*ast.Name
+    Name string
+    Arguments []*ast.Parameter
+    // Defaults holds the default values of the parameters, evaluated when
+    // the function is defined. It is indexed like Arguments and is nil for
+    // parameters without a default.
+    Defaults []Object
     Body *ast.BlockStatement
+    Scope *ast.Scope
     Env *Env
 }
 
@@ -139,8 +439,15 @@ func (f *Function) Inspect() string {
 
 type BuiltinFunction func(args ...Object) Object
 
+// KeywordFunction is a builtin accepting keyword arguments, kwargs is nil
+// when none are passed.
+type KeywordFunction func(args []Object, kwargs *Dict) Object
+
 type Bltin struct {
+    Name string
     Fn BuiltinFunction
+    // KwFn is called instead of Fn by builtins taking keyword arguments.
+    KwFn KeywordFunction
 }
 
 func (b *Bltin) Type() ObjectType {
@@ -175,3 +482,72 @@ func (l *List) Inspect() string {
     return out.String()
 }
 
+func (l *List) Iter() Iterator {
+    return &SliceIterator{Items: l.Arr}
+}
+
+func (l *List) Len() int {
+    return len(l.Arr)
+}
+
+// Tuple is an immutable sequence, it is hashable if its elements are.
+type Tuple struct {
+    Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType {
+    return TUPLE_OBJ
+}
+
+func (t *Tuple) Inspect() string {
+    var out bytes.Buffer
+
+    out.WriteString("tuple((")
+
+    elems := []string{}
+
+    for _, elem := range t.Elements {
+        elems = append(elems, elem.Inspect())
+    }
+
+    out.WriteString(strings.Join(elems, ", "))
+
+    // A single element keeps its comma, like in "(1,)".
+    if len(elems) == 1 {
+        out.WriteString(",")
+    }
+
+    out.WriteString("))")
+
+    return out.String()
+}
+
+func (t *Tuple) Iter() Iterator {
+    return &SliceIterator{Items: t.Elements}
+}
+
+func (t *Tuple) Len() int {
+    return len(t.Elements)
+}
+
+// Slice selects a part of a sequence, as in "xs[start:stop:step]". The
+// omitted bounds are None.
+type Slice struct {
+    Start Object
+    Stop Object
+    Step Object
+}
+
+func (s *Slice) Type() ObjectType {
+    return SLICE_OBJ
+}
+
+func (s *Slice) Inspect() string {
+    return fmt.Sprintf(
+        "slice(%s, %s, %s)",
+        s.Start.Inspect(),
+        s.Stop.Inspect(),
+        s.Step.Inspect(),
+    )
+}
+
@@ -0,0 +1,342 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/lexer"
+	"mxshs/pyinterpreter/token"
+)
+
+// maxSpecDepth is how deeply replacement fields can nest in format specs,
+// as in f"{x:{width}}".
+const maxSpecDepth = 2
+
+// parseFormattedString splits the f-string of the current token, whose
+// literal is its source text, into literal parts and replacement fields.
+func (p *Parser) parseFormattedString() *ast.FormattedString {
+    tok := p.curToken
+    src := tok.Literal
+
+    prefix := strings.IndexAny(src, "'\"")
+    quote := 1
+    if len(src) - prefix >= 6 && strings.Count(src[prefix:prefix + 3], src[prefix:prefix + 1]) == 3 {
+        quote = 3
+    }
+
+    body := src[prefix + quote:len(src) - quote]
+    raw := strings.ContainsAny(src[:prefix], "rR")
+
+    return p.parseFormatBody(tok, body, advance(tok.Pos, src[:prefix + quote]), raw, 0)
+}
+
+// parseFormatBody parses the body of an f-string, or of a format spec in
+// it, which starts at pos.
+func (p *Parser) parseFormatBody(
+    tok token.Token,
+    body string,
+    pos token.Position,
+    raw bool,
+    depth int) *ast.FormattedString {
+
+    fs := &ast.FormattedString{Token: tok}
+
+    var literal strings.Builder
+    literalPos := pos
+
+    flush := func() {
+        value := literal.String()
+        if !raw {
+            decoded, err := lexer.DecodeEscapes(value, false)
+            if err != nil {
+                p.errorAt(literalPos, "SyntaxError: %s", err.Error())
+            }
+
+            value = decoded
+        }
+
+        fs.Parts = append(
+            fs.Parts,
+            &ast.StringLiteral{
+                Token: token.Token{Type: token.STRING, Literal: value, Pos: literalPos},
+                Value: value,
+            },
+        )
+        literal.Reset()
+    }
+
+    for i := 0; i < len(body); {
+        switch {
+        case strings.HasPrefix(body[i:], "{{"), strings.HasPrefix(body[i:], "}}"):
+            literal.WriteByte(body[i])
+            i += 2
+        case body[i] == '}':
+            p.errorAt(
+                advance(pos, body[:i]),
+                "SyntaxError: f-string: single '}' is not allowed",
+            )
+            return fs
+        case body[i] == '{':
+            flush()
+
+            end, ok := p.parseReplacementField(fs, tok, body, i, pos, raw, depth)
+            if !ok {
+                return fs
+            }
+
+            i = end
+            literalPos = advance(pos, body[:i])
+        case body[i] == '\\' && i + 1 < len(body):
+            literal.WriteString(body[i:i + 2])
+            i += 2
+        default:
+            literal.WriteByte(body[i])
+            i += 1
+        }
+    }
+
+    flush()
+    fs.Parts = joinStringParts(fs.Parts)
+
+    return fs
+}
+
+// parseReplacementField parses the replacement field of body starting at
+// the "{" at start onto fs and returns the index following it.
+func (p *Parser) parseReplacementField(
+    fs *ast.FormattedString,
+    tok token.Token,
+    body string,
+    start int,
+    pos token.Position,
+    raw bool,
+    depth int) (int, bool) {
+
+    if depth >= maxSpecDepth {
+        p.errorAt(
+            advance(pos, body[:start]),
+            "SyntaxError: f-string: expressions nested too deeply",
+        )
+        return 0, false
+    }
+
+    exprStart := start + 1
+    i := skipExpression(body, exprStart)
+    exprSrc := body[exprStart:i]
+    exprPos := advance(pos, body[:exprStart])
+
+    if strings.TrimSpace(exprSrc) == "" {
+        next := "}"
+        if i < len(body) {
+            next = body[i:i + 1]
+        }
+
+        p.errorAt(
+            exprPos,
+            "SyntaxError: f-string: valid expression required before '%s'",
+            next,
+        )
+        return 0, false
+    }
+
+    field := &ast.FormattedValue{Token: tok}
+    field.Value = p.parseFieldExpression(exprSrc, exprPos)
+
+    // "{x=}" shows the expression text before its value, which defaults to
+    // its repr.
+    debug := i < len(body) && body[i] == '='
+    if debug {
+        i += 1
+        for i < len(body) && strings.ContainsRune(" \t\n", rune(body[i])) {
+            i += 1
+        }
+
+        text := body[exprStart:i]
+        fs.Parts = append(
+            fs.Parts,
+            &ast.StringLiteral{
+                Token: token.Token{Type: token.STRING, Literal: text, Pos: exprPos},
+                Value: text,
+            },
+        )
+    }
+
+    if i < len(body) && body[i] == '!' {
+        if i + 1 >= len(body) || !strings.Contains("sra", body[i + 1:i + 2]) {
+            p.errorAt(
+                advance(pos, body[:i]),
+                "SyntaxError: f-string: invalid conversion character: expected 's', 'r', or 'a'",
+            )
+            return 0, false
+        }
+
+        field.Conversion = body[i + 1:i + 2]
+        i += 2
+    }
+
+    if i < len(body) && body[i] == ':' {
+        specStart := i + 1
+        i = skipSpec(body, specStart)
+
+        field.Spec = p.parseFormatBody(
+            tok,
+            body[specStart:i],
+            advance(pos, body[:specStart]),
+            raw,
+            depth + 1,
+        )
+    }
+
+    if debug && field.Conversion == "" && field.Spec == nil {
+        field.Conversion = "r"
+    }
+
+    if i >= len(body) || body[i] != '}' {
+        p.errorAt(advance(pos, body[:i]), "SyntaxError: f-string: expecting '}'")
+        return 0, false
+    }
+
+    fs.Parts = append(fs.Parts, field)
+
+    return i + 1, true
+}
+
+// parseFieldExpression parses the expression of a replacement field with a
+// parser of its own, which shares the scope of p.
+func (p *Parser) parseFieldExpression(src string, pos token.Position) ast.Expression {
+    trimmed := strings.TrimLeft(src, " \t\n")
+    pos = advance(pos, src[:len(src) - len(trimmed)])
+
+    sub := GetParser(lexer.GetLexerAt(pos, trimmed))
+    sub.scope = p.scope
+
+    expr := sub.parseExpressionList(LOWEST)
+    if !sub.peekTokenIs(token.NEWL) && !sub.peekTokenIs(token.EOF) {
+        sub.errorAt(sub.peekToken.Pos, "SyntaxError: f-string: expecting '}'")
+    }
+
+    p.errors = append(p.errors, sub.SyntaxErrors()...)
+
+    return expr
+}
+
+// skipExpression returns the index where the expression of a replacement
+// field starting at start ends: at the first "}", "!", ":" or "=" outside
+// brackets and strings that isn't part of an operator.
+func skipExpression(body string, start int) int {
+    depth := 0
+
+    for i := start; i < len(body); i++ {
+        switch ch := body[i]; ch {
+        case '(', '[', '{':
+            depth += 1
+        case ')', ']':
+            depth -= 1
+        case '}':
+            if depth == 0 {
+                return i
+            }
+
+            depth -= 1
+        case '\'', '"':
+            end := strings.IndexByte(body[i + 1:], ch)
+            if end == -1 {
+                return len(body)
+            }
+
+            i += end + 1
+        case '!':
+            if depth == 0 && !strings.HasPrefix(body[i:], "!=") {
+                return i
+            }
+
+            i += 1
+        case ':':
+            if depth == 0 {
+                return i
+            }
+        case '=', '<', '>':
+            next := byte(0)
+            if i + 1 < len(body) {
+                next = body[i + 1]
+            }
+
+            if next == '=' {
+                i += 1
+            } else if ch == '=' && depth == 0 {
+                return i
+            }
+        }
+    }
+
+    return len(body)
+}
+
+// skipSpec returns the index of the "}" closing a format spec starting at
+// start, skipping the replacement fields nested in it.
+func skipSpec(body string, start int) int {
+    depth := 0
+
+    for i := start; i < len(body); i++ {
+        switch body[i] {
+        case '{':
+            depth += 1
+        case '}':
+            if depth == 0 {
+                return i
+            }
+
+            depth -= 1
+        }
+    }
+
+    return len(body)
+}
+
+// joinStringParts merges adjacent string literals among parts and drops
+// the empty ones.
+func joinStringParts(parts []ast.Expression) []ast.Expression {
+    joined := []ast.Expression{}
+
+    for _, part := range parts {
+        literal, ok := part.(*ast.StringLiteral)
+        if !ok {
+            joined = append(joined, part)
+            continue
+        }
+
+        if literal.Value == "" {
+            continue
+        }
+
+        if len(joined) != 0 {
+            last, ok := joined[len(joined) - 1].(*ast.StringLiteral)
+            if ok {
+                joined[len(joined) - 1] = &ast.StringLiteral{
+                    Token: last.Token,
+                    Value: last.Value + literal.Value,
+                }
+                continue
+            }
+        }
+
+        joined = append(joined, literal)
+    }
+
+    return joined
+}
+
+// advance returns the position following text when it starts at pos.
+func advance(pos token.Position, text string) token.Position {
+    pos.Offset += len(text)
+
+    if i := strings.LastIndexByte(text, '\n'); i != -1 {
+        pos.Line += strings.Count(text, "\n")
+        pos.Column = utf8.RuneCountInString(text[i:])
+    } else {
+        pos.Column += utf8.RuneCountInString(text)
+    }
+
+    return pos
+}
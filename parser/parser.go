@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 	"strconv"
+	"strings"
 
 	"mxshs/pyinterpreter/ast"
 	"mxshs/pyinterpreter/lexer"
@@ -12,12 +16,21 @@ import (
 const (
     _ int = iota
     LOWEST
-    EQUALS
-    LESSGREATER
+    // CONDITIONAL is the precedence of "a if cond else b".
+    CONDITIONAL
+    OR
+    AND
+    NOT
+    COMPARISON
+    BITOR
+    BITXOR
+    BITAND
+    SHIFT
     SUM
     PRODUCT
-    POWER
+    // Unary operators bind looser than "**", "-2 ** 2" is "-(2 ** 2)".
     PREFIX
+    POWER
     CALL
     INDEX
 )
@@ -28,19 +41,51 @@ const (
 // different parsing fn).
 
 var precedenceMap = map[token.TokenType]int{
-    token.EQ: EQUALS,
-    token.NOT_EQ: EQUALS,
-    token.LT: LESSGREATER,
-    token.GT: LESSGREATER,
-    token.GREATER_EQ: LESSGREATER,
-    token.LESS_EQ: LESSGREATER,
+    token.IF: CONDITIONAL,
+    token.OR: OR,
+    token.AND: AND,
+    token.EQ: COMPARISON,
+    token.NOT_EQ: COMPARISON,
+    token.LT: COMPARISON,
+    token.GT: COMPARISON,
+    token.GREATER_EQ: COMPARISON,
+    token.LESS_EQ: COMPARISON,
+    token.IN: COMPARISON,
+    token.IS: COMPARISON,
+    // As an infix operator "not" only starts "not in".
+    token.NOT: COMPARISON,
+    token.PIPE: BITOR,
+    token.CARET: BITXOR,
+    token.AMPERSAND: BITAND,
+    token.LSHIFT: SHIFT,
+    token.RSHIFT: SHIFT,
     token.PLUS: SUM,
     token.MINUS: SUM,
     token.SLASH: PRODUCT,
+    token.DOUBLE_SLASH: PRODUCT,
+    token.PERCENT: PRODUCT,
     token.STAR: PRODUCT,
     token.DOUBLE_STAR: POWER,
     token.LPAR: CALL,
     token.LBR: INDEX,
+    token.DOT: INDEX,
+}
+
+// augmentedAssignments maps the augmented assignment tokens to the binary
+// operator they apply.
+var augmentedAssignments = map[token.TokenType]string{
+    token.PLUS_ASSIGN: "+",
+    token.MINUS_ASSIGN: "-",
+    token.TIMES_ASSIGN: "*",
+    token.DIV_ASSIGN: "/",
+    token.FLOOR_DIV_ASSIGN: "//",
+    token.MOD_ASSIGN: "%",
+    token.POW_ASSIGN: "**",
+    token.AND_ASSIGN: "&",
+    token.OR_ASSIGN: "|",
+    token.XOR_ASSIGN: "^",
+    token.LSHIFT_ASSIGN: "<<",
+    token.RSHIFT_ASSIGN: ">>",
 }
 
 type Parser struct {
@@ -48,8 +93,16 @@ type Parser struct {
 
     curToken token.Token
     peekToken token.Token
-    Depth int
-    errors []string
+    errors []*lexer.SyntaxError
+    // recovering is set from a syntax error until the parser resumes at the
+    // next statement, errors found meanwhile are likely caused by the first
+    // one and aren't reported.
+    recovering bool
+    // loopDepth is the number of loops enclosing the current statement
+    // within the current function, break and continue need one.
+    loopDepth int
+    // scope is the function being parsed, nil at module level.
+    scope *funcScope
 
     prefixParsers map[token.TokenType]prefixParse
     infixParsers map[token.TokenType]infixParse
@@ -63,7 +116,7 @@ type (
 func GetParser(l *lexer.Lexer) *Parser {
     p := &Parser{
         l: l,
-        errors: []string{},
+        errors: []*lexer.SyntaxError{},
     }
 
     p.curToken = p.l.NextToken()
@@ -73,29 +126,52 @@ func GetParser(l *lexer.Lexer) *Parser {
     p.registerPrefix(token.NAME, p.parseName)
     p.registerPrefix(token.INT, p.parseIntegerLiteral)
     p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+    p.registerPrefix(token.IMAG, p.parseImaginaryLiteral)
     p.registerPrefix(token.BTRUE, p.parseBoolean)
     p.registerPrefix(token.BFALSE, p.parseBoolean)
+    p.registerPrefix(token.NONE, p.parseNone)
     p.registerPrefix(token.STRING, p.parseString)
+    p.registerPrefix(token.FSTRING, p.parseString)
+    p.registerPrefix(token.BYTES, p.parseBytes)
     p.registerPrefix(token.LPAR, p.parseGroupedExpression)
-    p.registerPrefix(token.IF, p.parseIfExpression)
     p.registerPrefix(token.MINUS, p.parsePrefixExpression)
     p.registerPrefix(token.BANG, p.parsePrefixExpression)
+    p.registerPrefix(token.TILDE, p.parsePrefixExpression)
+    p.registerPrefix(token.NOT, p.parseNotExpression)
     p.registerPrefix(token.STAR, p.parsePrefixExpression)
     p.registerPrefix(token.DOUBLE_STAR, p.parsePrefixExpression)
     p.registerPrefix(token.LBR, p.parseListExpression)
+    p.registerPrefix(token.LSQB, p.parseDictExpression)
+    p.registerPrefix(token.LAMBDA, p.parseLambdaExpression)
 
     p.infixParsers = make(map[token.TokenType]infixParse)
     p.registerInfix(token.LPAR, p.parseCallExpression)
-    p.registerInfix(token.EQ, p.parseInfixExpression)
-    p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
-    p.registerInfix(token.LT, p.parseInfixExpression)
-    p.registerInfix(token.GT, p.parseInfixExpression)
+    p.registerInfix(token.IF, p.parseConditionalExpression)
+    p.registerInfix(token.OR, p.parseInfixExpression)
+    p.registerInfix(token.AND, p.parseInfixExpression)
+    p.registerInfix(token.EQ, p.parseComparisonExpression)
+    p.registerInfix(token.NOT_EQ, p.parseComparisonExpression)
+    p.registerInfix(token.LT, p.parseComparisonExpression)
+    p.registerInfix(token.GT, p.parseComparisonExpression)
+    p.registerInfix(token.LESS_EQ, p.parseComparisonExpression)
+    p.registerInfix(token.GREATER_EQ, p.parseComparisonExpression)
+    p.registerInfix(token.IN, p.parseComparisonExpression)
+    p.registerInfix(token.NOT, p.parseComparisonExpression)
+    p.registerInfix(token.IS, p.parseComparisonExpression)
     p.registerInfix(token.PLUS, p.parseInfixExpression)
     p.registerInfix(token.MINUS, p.parseInfixExpression)
     p.registerInfix(token.SLASH, p.parseInfixExpression)
+    p.registerInfix(token.DOUBLE_SLASH, p.parseInfixExpression)
+    p.registerInfix(token.PERCENT, p.parseInfixExpression)
+    p.registerInfix(token.PIPE, p.parseInfixExpression)
+    p.registerInfix(token.CARET, p.parseInfixExpression)
+    p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+    p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+    p.registerInfix(token.RSHIFT, p.parseInfixExpression)
     p.registerInfix(token.STAR, p.parseInfixExpression)
     p.registerInfix(token.DOUBLE_STAR, p.parseInfixExpression)
     p.registerInfix(token.LBR, p.parseIndexExpression)
+    p.registerInfix(token.DOT, p.parseAttributeExpression)
 
     //p.nextToken()
     //p.nextToken()
@@ -103,33 +179,209 @@ func GetParser(l *lexer.Lexer) *Parser {
     return p
 }
 
+// Errors returns the syntax errors formatted with their positions.
 func (p *Parser) Errors() []string {
-    return p.errors
+    errors := []string{}
+    for _, err := range p.SyntaxErrors() {
+        errors = append(errors, err.Error())
+    }
+
+    return errors
+}
+
+// SyntaxErrors returns the errors found by the lexer (e.g. inconsistent
+// indentation) and the parser, ordered by their position.
+func (p *Parser) SyntaxErrors() []*lexer.SyntaxError {
+    errors := append(append([]*lexer.SyntaxError{}, p.l.SyntaxErrors()...), p.errors...)
+
+    sort.SliceStable(errors, func(i, j int) bool {
+        a, b := errors[i].Pos, errors[j].Pos
+        return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+    })
+
+    return errors
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-    msg := fmt.Sprintf(
-        "expected token of type: %s, got: %s",
-        t,
-        p.peekToken.Type,
-    )
-    p.errors = append(p.errors, msg)
+    p.unexpectedToken(p.peekToken, t)
+}
+
+// unexpectedToken reports that tok was found where a token of type
+// expected was, or where no token can follow if expected is empty.
+func (p *Parser) unexpectedToken(tok token.Token, expected token.TokenType) {
+    // The lexer has reported illegal tokens already.
+    if tok.Type == token.ILLEGAL {
+        p.recovering = true
+        return
+    }
+
+    if p.recovering {
+        return
+    }
+
+    msg := "invalid syntax"
+    if expected != "" {
+        msg = fmt.Sprintf(
+            "expected %s, found %s",
+            lexer.DescribeType(expected),
+            lexer.Describe(tok),
+        )
+    }
+
+    err := lexer.NewSyntaxError(tok.Pos, msg)
+    err.Expected = expected
+    err.Found = &tok
+
+    p.errors = append(p.errors, err)
+    p.recovering = true
+}
+
+// errorAt records a syntax error at pos, format may start with the class of
+// the error, e.g. "IndentationError: ...".
+func (p *Parser) errorAt(
+    pos token.Position, format string, args ...interface{}) {
+
+    if p.recovering {
+        return
+    }
+
+    p.errors = append(p.errors, lexer.NewSyntaxError(pos, fmt.Sprintf(format, args...)))
+    p.recovering = true
 }
 
 func (p *Parser) nextToken() {
     p.curToken = p.peekToken
-    p.Depth = p.l.GetDepth()
     p.peekToken = p.l.NextToken()
 }
 
+// parseStatement parses the statement at curToken. A statement with a
+// syntax error is dropped and the rest of it skipped, so that parsing
+// resumes at the next statement.
 func (p *Parser) parseStatement() ast.Statement {
+    compound := compoundStatements[p.curToken.Type]
+
+    statement := p.parseStatementKind()
+    if !p.recovering {
+        return statement
+    }
+
+    p.synchronize(compound)
+    p.recovering = false
+
+    return nil
+}
+
+// compoundStatements are the tokens starting statements with a block.
+var compoundStatements = map[token.TokenType]bool{
+    token.IF: true,
+    token.WHILE: true,
+    token.FOR: true,
+    token.FDEF: true,
+    token.CLASS: true,
+    token.TRY: true,
+}
+
+// clauseStatements are the tokens starting the clauses that continue a
+// compound statement.
+var clauseStatements = map[token.TokenType]bool{
+    token.ELIF: true,
+    token.ELSE: true,
+    token.EXCEPT: true,
+    token.FINALLY: true,
+}
+
+// synchronize skips the rest of the statement in which a syntax error was
+// found, up to the NEWL ending it. For a compound statement that includes
+// its blocks and the clauses continuing it, e.g. "else:".
+func (p *Parser) synchronize(compound bool) {
+    for {
+        for !p.tokenIs(token.NEWL) && !p.tokenIs(token.DEDENT) && !p.tokenIs(token.EOF) {
+            p.nextToken()
+        }
+
+        if !compound || !p.tokenIs(token.NEWL) {
+            return
+        }
+
+        if p.peekTokenIs(token.INDENT) {
+            p.skipBlock()
+        }
+
+        if !clauseStatements[p.peekToken.Type] {
+            return
+        }
+
+        p.nextToken()
+    }
+}
+
+// skipBlock skips the indented block following the NEWL at curToken, up to
+// the DEDENT closing it.
+func (p *Parser) skipBlock() {
+    depth := 0
+
+    for !p.tokenIs(token.EOF) {
+        p.nextToken()
+
+        switch p.curToken.Type {
+        case token.INDENT:
+            depth += 1
+        case token.DEDENT:
+            depth -= 1
+            if depth == 0 {
+                return
+            }
+        }
+    }
+}
+
+// endStatement consumes the NEWL ending a simple statement, anything else
+// following it on the line is a syntax error.
+func (p *Parser) endStatement() {
+    switch p.peekToken.Type {
+    case token.NEWL:
+        p.nextToken()
+    case token.DEDENT, token.EOF:
+    default:
+        p.unexpectedToken(p.peekToken, "")
+    }
+}
+
+func (p *Parser) parseStatementKind() ast.Statement {
     switch tok := p.curToken.Type; {
+    case tok == token.NEWL || tok == token.DEDENT:
+        return nil
+    case tok == token.INDENT:
+        p.errorAt(p.curToken.Pos, "IndentationError: unexpected indent")
+        // The indented statements are parsed as if they weren't indented.
+        p.recovering = false
+        return nil
     case tok == token.NAME && p.peekTokenIs(token.ASSIGN):
         return p.parseAssignStatement()
     case tok == token.FDEF:
         return p.parseFunctionStatement()
+    case tok == token.CLASS:
+        return p.parseClassStatement()
+    case tok == token.IF:
+        return p.parseIfStatement()
+    case tok == token.WHILE:
+        return p.parseWhileStatement()
+    case tok == token.FOR:
+        return p.parseForStatement()
+    case tok == token.BREAK:
+        return p.parseBreakStatement()
+    case tok == token.CONTINUE:
+        return p.parseContinueStatement()
     case tok == token.RETURN:
         return p.parseReturnStatement()
+    case tok == token.PASS:
+        return p.parsePassStatement()
+    case tok == token.TRY:
+        return p.parseTryStatement()
+    case tok == token.RAISE:
+        return p.parseRaiseStatement()
+    case tok == token.GLOBAL || tok == token.NONLOCAL:
+        return p.parseGlobalStatement()
     default:
         return p.parseExpressionStatement()
     }
@@ -143,54 +395,176 @@ func (p *Parser) parseAssignStatement() *ast.AssignStatement {
     statement := &ast.AssignStatement{Token: p.curToken}
 
     statement.Name = &ast.Name{Token: tok, Value: literal}
+    p.bind(statement.Name)
 
     p.nextToken()
 
-    statement.Value = p.parseExpression(LOWEST)
+    statement.Value = p.parseExpressionList(LOWEST)
 
-    if p.peekTokenIs(token.NEWL) {
-        p.nextToken()
+    p.endStatement()
+
+    return statement
+}
+
+// parseTargetAssignStatement parses an assignment whose left hand side was
+// already parsed as an expression, e.g. a subscript, an attribute or a
+// tuple of targets.
+func (p *Parser) parseTargetAssignStatement(
+    target ast.Expression) *ast.AssignStatement {
+
+    p.checkTarget(target)
+
+    p.nextToken()
+
+    statement := &ast.AssignStatement{Token: p.curToken, Target: target}
+
+    p.nextToken()
+
+    statement.Value = p.parseExpressionList(LOWEST)
+
+    p.endStatement()
+
+    return statement
+}
+
+// parseAugAssignStatement parses an augmented assignment, its target is a
+// single name, subscript or attribute.
+func (p *Parser) parseAugAssignStatement(
+    target ast.Expression) *ast.AugAssignStatement {
+
+    switch target := target.(type) {
+    case *ast.Name:
+        p.bind(target)
+    case *ast.IndexExpression, *ast.AttributeExpression, nil:
+    default:
+        // A target that failed to parse is already reported.
+        if p.recovering {
+            break
+        }
+
+        p.errorAt(
+            target.Pos(),
+            "SyntaxError: '%s' is an illegal expression for augmented assignment",
+            expressionKind(target),
+        )
+    }
+
+    p.nextToken()
+
+    statement := &ast.AugAssignStatement{
+        Token: p.curToken,
+        Target: target,
+        Operator: augmentedAssignments[p.curToken.Type],
     }
 
+    p.nextToken()
+
+    statement.Value = p.parseExpressionList(LOWEST)
+
+    p.endStatement()
+
     return statement
 }
 
+// expressionKind names the kind of expr in error messages like Python does,
+// e.g. "tuple" or "function call".
+func expressionKind(expr ast.Expression) string {
+    switch expr := expr.(type) {
+    case *ast.TupleLiteral:
+        return "tuple"
+    case *ast.ListLiteral:
+        return "list"
+    case *ast.DictLiteral:
+        return "dict literal"
+    case *ast.CallExpression:
+        return "function call"
+    case *ast.LambdaExpression:
+        return "lambda"
+    case *ast.ConditionalExpression:
+        return "conditional expression"
+    case *ast.ComparisonExpression:
+        return "comparison"
+    case *ast.InfixExpression:
+        if precedenceMap[expr.Token.Type] == COMPARISON {
+            return "comparison"
+        }
+    case *ast.FormattedString:
+        return "f-string expression"
+    case *ast.NoneLiteral:
+        return "None"
+    case *ast.Boolean:
+        if expr.Value {
+            return "True"
+        }
+
+        return "False"
+    case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.ImaginaryLiteral,
+        *ast.StringLiteral, *ast.BytesLiteral:
+        return "literal"
+    case *ast.PrefixExpression:
+        if expr.Operator == "*" {
+            return "starred"
+        }
+    }
+
+    return "expression"
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
     statement := &ast.ReturnStatement{Token: p.curToken}
 
+    // A bare return returns None.
+    switch p.peekToken.Type {
+    case token.NEWL:
+        p.nextToken()
+        return statement
+    case token.DEDENT, token.EOF:
+        return statement
+    }
+
     p.nextToken()
 
-    statement.ReturnValue = p.parseExpression(LOWEST)
+    statement.ReturnValue = p.parseExpressionList(LOWEST)
 
-    if p.peekTokenIs(token.NEWL) {
-        p.nextToken()
-    }
+    p.endStatement()
 
     return statement
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseExpressionStatement() ast.Statement {
     statement := &ast.ExpressionStatement{Token: p.curToken}
 
-    statement.Expression = p.parseExpression(LOWEST)
+    statement.Expression = p.parseExpressionList(LOWEST)
 
-    if p.peekTokenIs(token.NEWL) {
-        p.nextToken()
+    if p.peekTokenIs(token.ASSIGN) {
+        return p.parseTargetAssignStatement(statement.Expression)
+    }
+
+    if _, ok := augmentedAssignments[p.peekToken.Type]; ok {
+        return p.parseAugAssignStatement(statement.Expression)
     }
 
+    p.endStatement()
+
     return statement
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
     prefix := p.prefixParsers[p.curToken.Type]
     if prefix == nil {
-        p.addNoPrefixParseError(p.curToken.Type)
+        p.unexpectedToken(p.curToken, "")
         return nil
     }
 
     leftExp := prefix()
 
     for !p.peekTokenIs(token.NEWL) && precedence < p.peekPrecedence() {
+        // A failed operand leaves nothing to apply operators to, the
+        // statement is skipped once the error is reported.
+        if leftExp == nil || p.recovering {
+            return nil
+        }
+
         infix := p.infixParsers[p.peekToken.Type]
         if infix == nil {
             return leftExp
@@ -205,12 +579,39 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
         }
     }
 
+    if p.recovering {
+        return nil
+    }
+
     return leftExp
 }
 
-func (p *Parser) addNoPrefixParseError(t token.TokenType) {
-    msg := fmt.Sprintf("prefix parse function not found for type %s %s", t, p.peekToken.Literal)
-    p.errors = append(p.errors, msg)
+// parseExpressionList parses an expression, followed by more comma
+// separated ones in a tuple without parentheses, e.g. "b, a" in
+// "a, b = b, a".
+func (p *Parser) parseExpressionList(precedence int) ast.Expression {
+    tok := p.curToken
+
+    exp := p.parseExpression(precedence)
+    if !p.peekTokenIs(token.COMMA) {
+        return exp
+    }
+
+    tuple := &ast.TupleLiteral{Token: tok, Elements: []ast.Expression{exp}}
+
+    for p.peekTokenIs(token.COMMA) {
+        p.nextToken()
+
+        // A trailing comma is allowed, e.g. in "a, = xs".
+        if _, ok := p.prefixParsers[p.peekToken.Type]; !ok {
+            break
+        }
+
+        p.nextToken()
+        tuple.Elements = append(tuple.Elements, p.parseExpression(precedence))
+    }
+
+    return tuple
 }
 
 func (p *Parser) parseName() ast.Expression {
@@ -220,14 +621,24 @@ func (p *Parser) parseName() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
     literal := &ast.IntegerLiteral{Token: p.curToken}
 
-    value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-    if err != nil {
+    digits := strings.ReplaceAll(p.curToken.Literal, "_", "")
+
+    value, err := strconv.ParseInt(digits, 0, 64)
+    // Literals that overflow an int64 are kept as big ints.
+    if errors.Is(err, strconv.ErrRange) {
+        bigValue, ok := new(big.Int).SetString(digits, 0)
+        if ok {
+            literal.Big = bigValue
+            return literal
+        }
+    }
 
-        msg := fmt.Sprintf(
+    if err != nil {
+        p.errorAt(
+            p.curToken.Pos,
             "error during parsing %q as integer",
             p.curToken.Literal,
         )
-        p.errors = append(p.errors, msg)
         return nil
     }
 
@@ -239,13 +650,21 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 func (p *Parser) parseFloatLiteral() ast.Expression {
     literal := &ast.FloatLiteral{Token: p.curToken}
 
-    value, err := strconv.ParseFloat(p.curToken.Literal, 64)
-    if err != nil {
-        msg := fmt.Sprintf(
-            "error during parsing %q as float",
-            p.curToken.Literal,
-        )
-        p.errors = append(p.errors, msg)
+    value, ok := p.parseFloat(p.curToken.Literal)
+    if !ok {
+        return nil
+    }
+
+    literal.Value = value
+
+    return literal
+}
+
+func (p *Parser) parseImaginaryLiteral() ast.Expression {
+    literal := &ast.ImaginaryLiteral{Token: p.curToken}
+
+    value, ok := p.parseFloat(strings.TrimRight(p.curToken.Literal, "jJ"))
+    if !ok {
         return nil
     }
 
@@ -254,16 +673,32 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
     return literal
 }
 
+// parseFloat parses the digits of a float literal, literals too large for
+// a float64 are infinite.
+func (p *Parser) parseFloat(literal string) (float64, bool) {
+    value, err := strconv.ParseFloat(strings.ReplaceAll(literal, "_", ""), 64)
+    if err != nil && !errors.Is(err, strconv.ErrRange) {
+        p.errorAt(
+            p.curToken.Pos,
+            "error during parsing %q as float",
+            p.curToken.Literal,
+        )
+        return 0, false
+    }
+
+    return value, true
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
     literal := &ast.Boolean{Token: p.curToken}
 
     value, err := strconv.ParseBool(p.curToken.Literal)
     if err != nil {
-        msg := fmt.Sprintf(
+        p.errorAt(
+            p.curToken.Pos,
             "boolean parse error. Cannot convert %s to boolean",
             p.curToken.Literal,
         )
-        p.errors = append(p.errors, msg)
         return nil
     }
 
@@ -272,12 +707,87 @@ func (p *Parser) parseBoolean() ast.Expression {
     return literal
 }
 
+func (p *Parser) parseNone() ast.Expression {
+    return &ast.NoneLiteral{Token: p.curToken}
+}
+
+// parseString parses a run of adjacent string literals, which are joined
+// into one. If any of them is an f-string, so is the result.
 func (p *Parser) parseString() ast.Expression {
-    literal := &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+    tok := p.curToken
+    parts := []ast.Expression{}
+    formatted := false
+
+    for {
+        switch p.curToken.Type {
+        case token.FSTRING:
+            formatted = true
+            parts = append(parts, p.parseFormattedString().Parts...)
+        case token.STRING:
+            parts = append(
+                parts,
+                &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal},
+            )
+        default:
+            p.mixedLiteralsError()
+        }
+
+        if !p.peekStringIs() {
+            break
+        }
+
+        p.nextToken()
+    }
+
+    parts = joinStringParts(parts)
+
+    if !formatted {
+        if len(parts) == 0 {
+            return &ast.StringLiteral{Token: tok, Value: ""}
+        }
+
+        literal := parts[0].(*ast.StringLiteral)
+        literal.Token.Literal = literal.Value
+
+        return literal
+    }
+
+    return &ast.FormattedString{Token: tok, Parts: parts}
+}
+
+// parseBytes parses a run of adjacent bytes literals, which are joined into
+// one.
+func (p *Parser) parseBytes() ast.Expression {
+    literal := &ast.BytesLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+    for p.peekStringIs() {
+        p.nextToken()
+
+        if !p.tokenIs(token.BYTES) {
+            p.mixedLiteralsError()
+            continue
+        }
+
+        literal.Value += p.curToken.Literal
+    }
 
     return literal
 }
 
+// peekStringIs reports whether the next token is a string or bytes literal.
+func (p *Parser) peekStringIs() bool {
+    return p.peekTokenIs(token.STRING) ||
+        p.peekTokenIs(token.FSTRING) ||
+        p.peekTokenIs(token.BYTES)
+}
+
+func (p *Parser) mixedLiteralsError() {
+    p.errorAt(
+        p.curToken.Pos,
+        "SyntaxError: cannot mix bytes and nonbytes literals",
+    )
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
     expression := &ast.PrefixExpression{
         Token: p.curToken,
@@ -291,110 +801,512 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
     return expression
 }
 
-func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
-    expression := &ast.InfixExpression{
+// parseNotExpression parses "not", which binds looser than comparisons,
+// e.g. "not a == b" is "not (a == b)".
+func (p *Parser) parseNotExpression() ast.Expression {
+    expression := &ast.PrefixExpression{
         Token: p.curToken,
         Operator: p.curToken.Literal,
-        Left: left,
     }
 
-    precedence := p.precedenceIs()
     p.nextToken()
-    expression.Right = p.parseExpression(precedence)
+
+    expression.Right = p.parseExpression(NOT)
 
     return expression
 }
 
-func (p *Parser) parseGroupedExpression() ast.Expression {
+// parseComparisonExpression parses a comparison. Consecutive comparisons
+// are chained like in Python, "a < b < c" is "a < b and b < c" except that
+// b is only evaluated once.
+func (p *Parser) parseComparisonExpression(left ast.Expression) ast.Expression {
+    tok := p.curToken
+    operator := p.parseComparisonOperator()
+
     p.nextToken()
+    right := p.parseExpression(COMPARISON)
+
+    if p.peekPrecedence() != COMPARISON {
+        return &ast.InfixExpression{
+            Token: tok,
+            Operator: operator,
+            Left: left,
+            Right: right,
+        }
+    }
 
-    exp := p.parseExpression(LOWEST)
+    chain := &ast.ComparisonExpression{
+        Token: tok,
+        Operands: []ast.Expression{left, right},
+        Operators: []string{operator},
+    }
 
-    if !p.expectPeek(token.RPAR) {
-        //fmt.Printf("wanted %s, got %s and %s", token.RPAR, p.curToken.Literal, p.peekToken.Literal)
-        return nil
+    for p.peekPrecedence() == COMPARISON {
+        p.nextToken()
+        chain.Operators = append(chain.Operators, p.parseComparisonOperator())
+
+        p.nextToken()
+        chain.Operands = append(chain.Operands, p.parseExpression(COMPARISON))
     }
 
-    return exp
+    return chain
 }
 
-func (p *Parser) parseIfExpression() ast.Expression {
-    expression := &ast.IfExpression{Token: p.curToken}
-    
-    //if !p.expectPeek(token.LPAR) {
-      //  return nil
-    //}
-
-    p.nextToken()
+// parseComparisonOperator returns the comparison operator at the current
+// token, joining the two words of "not in" and "is not".
+func (p *Parser) parseComparisonOperator() string {
+    switch {
+    case p.tokenIs(token.NOT):
+        p.expectPeek(token.IN)
+        return "not in"
+    case p.tokenIs(token.IS) && p.peekTokenIs(token.NOT):
+        p.nextToken()
+        return "is not"
+    default:
+        return p.curToken.Literal
+    }
+}
 
-    expression.Condition = p.parseExpression(LOWEST)
+func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+    expression := &ast.InfixExpression{
+        Token: p.curToken,
+        Operator: p.curToken.Literal,
+        Left: left,
+    }
 
-    //if !p.expectPeek(token.RPAR) && !p.expectPeek(token.COLON) && !p.expectPeek(token.LPAR) {
-      //  return nil
-    //}
+    precedence := p.precedenceIs()
 
-    //if !p.expectPeek(token.RPAR) {
-      //  return nil
-    //}
-    if p.curToken.Type != token.COLON && !p.expectPeek(token.COLON) {
-        return nil
+    // "**" is right associative, "2 ** 3 ** 2" is "2 ** (3 ** 2)".
+    if expression.Operator == "**" {
+        precedence = PREFIX
     }
 
     p.nextToken()
+    expression.Right = p.parseExpression(precedence)
 
-    if p.tokenIs(token.NEWL) {
-        expression.Consequence = p.parseBlockStatement()
-    } else {
-        expression.Consequence = p.parseInlineStatement()
+    return expression
+}
+
+// parseGroupedExpression parses a parenthesized expression, or a tuple if
+// the parentheses are empty or hold a comma, e.g. "()", "(a,)" or "(a, b)".
+func (p *Parser) parseGroupedExpression() ast.Expression {
+    tok := p.curToken
+
+    if p.peekTokenIs(token.RPAR) {
+        p.nextToken()
+        return &ast.TupleLiteral{Token: tok, Elements: []ast.Expression{}}
     }
 
     p.nextToken()
 
-    if p.curToken.Type == token.ELSE {
-        if p.curToken.Type != token.COLON && !p.expectPeek(token.COLON) {
+    exp := p.parseExpression(LOWEST)
+
+    if !p.peekTokenIs(token.COMMA) {
+        if !p.expectPeek(token.RPAR) {
+            //fmt.Printf("wanted %s, got %s and %s", token.RPAR, p.curToken.Literal, p.peekToken.Literal)
             return nil
         }
 
-        p.nextToken()
-
-        if p.tokenIs(token.NEWL) {
-            expression.Alternative = p.parseBlockStatement()
-        } else {
-            expression.Alternative = p.parseInlineStatement()
-        }
+        return exp
     }
 
-    return expression
-}
-
-func (p *Parser) parseBlockStatement() *ast.BlockStatement {
-    block := &ast.BlockStatement{Token: p.curToken}
-    block.Statements = []ast.Statement{}
-    p.nextToken()
-    currDepth := p.Depth
+    tuple := &ast.TupleLiteral{Token: tok, Elements: []ast.Expression{exp}}
 
-    for !p.tokenIs(token.EOF) && p.Depth >= currDepth {
-        statement := p.parseStatement()
-        if statement != nil {
-            block.Statements = append(block.Statements, statement)
-        }
+    for p.peekTokenIs(token.COMMA) {
+        p.nextToken()
 
-        // TODO: Redo handling of blocks
-        // Currently this thing breaks out of the loop if next token is in
-        // outer scope. However, this is bad because I can just track the 
-        // depth of the next token initially, without having to introduce
-        // branching here.
-        if p.l.GetDepth() < currDepth {
+        if p.peekTokenIs(token.RPAR) {
             break
         }
-    
+
         p.nextToken()
+        tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
     }
 
-  
-    return block
-}
-
+    if !p.expectPeek(token.RPAR) {
+        return nil
+    }
+
+    return tuple
+}
+
+// parseIfStatement parses an if statement with its elif and else clauses.
+// Unlike other expressions it ends with its block, so it is never continued
+// by an infix operator on the following line.
+func (p *Parser) parseIfStatement() *ast.ExpressionStatement {
+    statement := &ast.ExpressionStatement{Token: p.curToken}
+
+    statement.Expression = p.parseIfExpression()
+
+    return statement
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+    expression := &ast.IfExpression{Token: p.curToken}
+
+    p.nextToken()
+
+    expression.Condition = p.parseExpression(LOWEST)
+
+    if !p.expectPeek(token.COLON) {
+        return nil
+    }
+
+    expression.Consequence = p.parseSuite()
+
+    for p.peekTokenIs(token.ELIF) {
+        p.nextToken()
+
+        clause := &ast.ElifClause{Token: p.curToken}
+
+        p.nextToken()
+
+        clause.Condition = p.parseExpression(LOWEST)
+
+        if !p.expectPeek(token.COLON) {
+            return nil
+        }
+
+        clause.Consequence = p.parseSuite()
+        expression.Elifs = append(expression.Elifs, clause)
+    }
+
+    if p.peekTokenIs(token.ELSE) {
+        p.nextToken()
+
+        if !p.expectPeek(token.COLON) {
+            return nil
+        }
+
+        expression.Alternative = p.parseSuite()
+    }
+
+    return expression
+}
+
+// parseConditionalExpression parses "a if cond else b" following its
+// consequence. The condition binds tighter than a conditional expression,
+// the alternative may be one, so "a if x else b if y else c" is
+// "a if x else (b if y else c)".
+func (p *Parser) parseConditionalExpression(consequence ast.Expression) ast.Expression {
+    expression := &ast.ConditionalExpression{
+        Token: p.curToken,
+        Consequence: consequence,
+    }
+
+    p.nextToken()
+
+    expression.Condition = p.parseExpression(CONDITIONAL)
+
+    if !p.expectPeek(token.ELSE) {
+        return nil
+    }
+
+    p.nextToken()
+
+    expression.Alternative = p.parseExpression(LOWEST)
+
+    return expression
+}
+
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+    statement := &ast.WhileStatement{Token: p.curToken}
+
+    p.nextToken()
+
+    statement.Condition = p.parseExpression(LOWEST)
+
+    if !p.expectPeek(token.COLON) {
+        return nil
+    }
+
+    p.loopDepth += 1
+    statement.Body = p.parseSuite()
+    p.loopDepth -= 1
+
+    if p.peekTokenIs(token.ELSE) {
+        p.nextToken()
+
+        if !p.expectPeek(token.COLON) {
+            return nil
+        }
+
+        statement.Alternative = p.parseSuite()
+    }
+
+    return statement
+}
+
+func (p *Parser) parseForStatement() *ast.ForStatement {
+    statement := &ast.ForStatement{Token: p.curToken}
+
+    p.nextToken()
+
+    // The target stops before "in", which would be parsed as a comparison.
+    statement.Target = p.parseExpressionList(COMPARISON)
+    p.checkTarget(statement.Target)
+
+    if !p.expectPeek(token.IN) {
+        return nil
+    }
+
+    p.nextToken()
+
+    statement.Iterable = p.parseExpressionList(LOWEST)
+
+    if !p.expectPeek(token.COLON) {
+        return nil
+    }
+
+    p.loopDepth += 1
+    statement.Body = p.parseSuite()
+    p.loopDepth -= 1
+
+    if p.peekTokenIs(token.ELSE) {
+        p.nextToken()
+
+        if !p.expectPeek(token.COLON) {
+            return nil
+        }
+
+        statement.Alternative = p.parseSuite()
+    }
+
+    return statement
+}
+
+// checkTarget reports an error if target can't be assigned to, and binds
+// the names it assigns in the current scope.
+func (p *Parser) checkTarget(target ast.Expression) {
+    // A target that failed to parse is already reported.
+    if target == nil || p.recovering {
+        return
+    }
+
+    switch target := target.(type) {
+    case *ast.Name:
+        p.bind(target)
+    case *ast.TupleLiteral:
+        p.checkTargets(target.Elements)
+    case *ast.ListLiteral:
+        p.checkTargets(target.Arr)
+    case *ast.IndexExpression, *ast.AttributeExpression:
+    case *ast.PrefixExpression:
+        if target.Operator == "*" {
+            p.errorAt(
+                target.Pos(),
+                "SyntaxError: starred assignment target must be in a list or tuple",
+            )
+            return
+        }
+
+        p.errorAt(
+            target.Pos(),
+            "SyntaxError: cannot assign to %s",
+            expressionKind(target),
+        )
+    default:
+        p.errorAt(
+            target.Pos(),
+            "SyntaxError: cannot assign to %s",
+            expressionKind(target),
+        )
+    }
+}
+
+// checkTargets checks the elements of a tuple or list target, one of which
+// may be starred to collect the remaining values.
+func (p *Parser) checkTargets(targets []ast.Expression) {
+    starred := false
+
+    for _, target := range targets {
+        prefix, ok := target.(*ast.PrefixExpression)
+        if !ok || prefix.Operator != "*" {
+            p.checkTarget(target)
+            continue
+        }
+
+        if starred {
+            p.errorAt(
+                prefix.Pos(),
+                "SyntaxError: multiple starred expressions in assignment",
+            )
+        }
+
+        starred = true
+        p.checkTarget(prefix.Right)
+    }
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+    statement := &ast.BreakStatement{Token: p.curToken}
+
+    if p.loopDepth == 0 {
+        p.errorAt(p.curToken.Pos, "SyntaxError: 'break' outside loop")
+    }
+
+    p.endStatement()
+
+    return statement
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+    statement := &ast.ContinueStatement{Token: p.curToken}
+
+    if p.loopDepth == 0 {
+        p.errorAt(
+            p.curToken.Pos,
+            "SyntaxError: 'continue' not properly in loop",
+        )
+    }
+
+    p.endStatement()
+
+    return statement
+}
+
+func (p *Parser) parsePassStatement() *ast.PassStatement {
+    statement := &ast.PassStatement{Token: p.curToken}
+
+    p.endStatement()
+
+    return statement
+}
+
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+    statement := &ast.TryStatement{Token: p.curToken}
+
+    if !p.expectPeek(token.COLON) {
+        return nil
+    }
+
+    statement.Body = p.parseSuite()
+
+    for p.peekTokenIs(token.EXCEPT) {
+        p.nextToken()
+
+        handler := p.parseExceptClause()
+        if handler == nil {
+            return nil
+        }
+
+        statement.Handlers = append(statement.Handlers, handler)
+    }
+
+    if len(statement.Handlers) != 0 && p.peekTokenIs(token.ELSE) {
+        p.nextToken()
+
+        if !p.expectPeek(token.COLON) {
+            return nil
+        }
+
+        statement.Alternative = p.parseSuite()
+    }
+
+    if p.peekTokenIs(token.FINALLY) {
+        p.nextToken()
+
+        if !p.expectPeek(token.COLON) {
+            return nil
+        }
+
+        statement.Finally = p.parseSuite()
+    }
+
+    if len(statement.Handlers) == 0 && statement.Finally == nil {
+        p.errorAt(
+            p.peekToken.Pos,
+            "SyntaxError: expected 'except' or 'finally' block",
+        )
+        return nil
+    }
+
+    return statement
+}
+
+func (p *Parser) parseExceptClause() *ast.ExceptClause {
+    clause := &ast.ExceptClause{Token: p.curToken}
+
+    if !p.peekTokenIs(token.COLON) {
+        p.nextToken()
+
+        clause.Class = p.parseExpression(LOWEST)
+
+        if p.peekTokenIs(token.AS) {
+            p.nextToken()
+
+            if !p.expectPeek(token.NAME) {
+                return nil
+            }
+
+            clause.Name = p.parseName().(*ast.Name)
+            p.bind(clause.Name)
+        }
+    }
+
+    if !p.expectPeek(token.COLON) {
+        return nil
+    }
+
+    clause.Body = p.parseSuite()
+
+    return clause
+}
+
+func (p *Parser) parseRaiseStatement() *ast.RaiseStatement {
+    statement := &ast.RaiseStatement{Token: p.curToken}
+
+    if !p.peekTokenIs(token.NEWL) && !p.peekTokenIs(token.EOF) {
+        p.nextToken()
+
+        statement.Value = p.parseExpression(LOWEST)
+    }
+
+    p.endStatement()
+
+    return statement
+}
+
+// parseSuite parses the body following a colon, which is either an
+// indented block or statements on the same line. It leaves curToken on the
+// DEDENT or NEWL that terminates the body.
+func (p *Parser) parseSuite() *ast.BlockStatement {
+    p.nextToken()
+
+    if p.tokenIs(token.NEWL) {
+        return p.parseBlockStatement()
+    }
+
+    return p.parseInlineStatement()
+}
+
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+    block := &ast.BlockStatement{Token: p.curToken}
+    block.Statements = []ast.Statement{}
+
+    if !p.peekTokenIs(token.INDENT) {
+        p.errorAt(
+            p.peekToken.Pos,
+            "IndentationError: expected an indented block",
+        )
+        return block
+    }
+
+    p.nextToken()
+    p.nextToken()
+
+    for !p.tokenIs(token.DEDENT) && !p.tokenIs(token.EOF) {
+        statement := p.parseStatement()
+        if statement != nil {
+            block.Statements = append(block.Statements, statement)
+        }
+
+        p.nextToken()
+    }
+
+    return block
+}
+
 func (p *Parser) parseInlineStatement() *ast.BlockStatement {
     inline := &ast.BlockStatement{Token: p.curToken}
     inline.Statements = []ast.Statement{}
@@ -427,85 +1339,282 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
     }
 
     statement.Name = p.parseName().(*ast.Name)
+    p.bind(statement.Name)
     
     if !p.expectPeek(token.LPAR) {
         return nil
     }
 
-    statement.Arguments = p.parseFunctionArguments()
+    statement.Arguments = p.parseFunctionArguments(token.RPAR)
 
     if !p.expectPeek(token.COLON) {
         return nil
     }
 
-    p.nextToken()
-    
-    if p.tokenIs(token.NEWL) {
-        statement.Body = p.parseBlockStatement()
-    } else {
-        statement.Body = p.parseInlineStatement()
-    }
+    // Loops outside of the function don't enclose its body.
+    loopDepth := p.loopDepth
+    p.loopDepth = 0
+    p.openScope(statement.Arguments)
+    statement.Body = p.parseSuite()
+    statement.Scope = p.closeScope()
+    p.loopDepth = loopDepth
 
     return statement
 }
 
-func (p *Parser) parseFunctionArguments() []*ast.Name {
-    args := []*ast.Name{}
+// parseFunctionArguments parses a parameter list up to the end token, i.e.
+// the closing parenthesis of a definition or the colon of a lambda.
+func (p *Parser) parseFunctionArguments(end token.TokenType) []*ast.Parameter {
+    params := []*ast.Parameter{}
+    names := map[string]bool{}
+
+    kind := ast.PositionalParam
+    var bareStar, varKeyword *ast.Parameter
+    hasDefault := false
+
+    for !p.peekTokenIs(end) {
+        p.nextToken()
+
+        if varKeyword != nil {
+            p.errorAt(
+                p.curToken.Pos,
+                "SyntaxError: arguments cannot follow var-keyword argument",
+            )
+            varKeyword = nil
+        }
+
+        param := p.parseParameter(kind)
+        if param == nil {
+            return nil
+        }
+
+        switch param.Kind {
+        case ast.PositionalParam:
+            if param.Default != nil {
+                hasDefault = true
+            } else if hasDefault {
+                p.errorAt(
+                    param.Pos(),
+                    "SyntaxError: non-default argument follows default argument",
+                )
+            }
+        case ast.KeywordOnlyParam:
+            bareStar = nil
+        case ast.VarArgsParam:
+            if kind != ast.PositionalParam {
+                p.errorAt(
+                    param.Pos(),
+                    "SyntaxError: * argument may appear only once",
+                )
+            }
+
+            kind = ast.KeywordOnlyParam
+            if param.Name == nil {
+                bareStar = param
+            }
+        case ast.VarKeywordParam:
+            varKeyword = param
+        }
+
+        if param.Name != nil {
+            if names[param.Name.Value] {
+                p.errorAt(
+                    param.Name.Pos(),
+                    "SyntaxError: duplicate argument '%s' in function definition",
+                    param.Name.Value,
+                )
+            }
+
+            names[param.Name.Value] = true
+        }
+
+        params = append(params, param)
+
+        if !p.peekTokenIs(token.COMMA) {
+            break
+        }
 
-    if p.peekTokenIs(token.RPAR) {
         p.nextToken()
-        return args
     }
 
-    p.nextToken()
+    if bareStar != nil {
+        p.errorAt(bareStar.Pos(), "SyntaxError: named arguments must follow bare *")
+    }
 
-    name := &ast.Name{Token: p.curToken, Value: p.curToken.Literal}
-    args = append(args, name)
+    if !p.expectPeek(end) {
+        return nil
+    }
+
+    return params
+}
+
+// parseParameter parses a single parameter: a name with an optional
+// default, *args, **kwargs or a bare *. Plain names get the given kind.
+func (p *Parser) parseParameter(kind ast.ParameterKind) *ast.Parameter {
+    param := &ast.Parameter{Token: p.curToken, Kind: kind}
+
+    switch p.curToken.Type {
+    case token.STAR:
+        param.Kind = ast.VarArgsParam
+
+        if !p.peekTokenIs(token.NAME) {
+            return param
+        }
 
-    for p.peekTokenIs(token.COMMA) {
         p.nextToken()
+    case token.DOUBLE_STAR:
+        param.Kind = ast.VarKeywordParam
         p.nextToken()
-        name := &ast.Name{Token: p.curToken, Value: p.curToken.Literal}
-        args = append(args, name)
     }
 
-    if !p.expectPeek(token.RPAR) {
+    if !p.tokenIs(token.NAME) {
+        p.errorAt(
+            p.curToken.Pos,
+            "SyntaxError: expected parameter name, got: %s",
+            p.curToken.Type,
+        )
         return nil
     }
 
-    return args
+    param.Name = &ast.Name{Token: p.curToken, Value: p.curToken.Literal}
+
+    if !p.peekTokenIs(token.ASSIGN) {
+        return param
+    }
+
+    p.nextToken()
+
+    switch param.Kind {
+    case ast.VarArgsParam:
+        p.errorAt(
+            p.curToken.Pos,
+            "SyntaxError: var-positional argument cannot have default value",
+        )
+    case ast.VarKeywordParam:
+        p.errorAt(
+            p.curToken.Pos,
+            "SyntaxError: var-keyword argument cannot have default value",
+        )
+    }
+
+    p.nextToken()
+
+    param.Default = p.parseExpression(LOWEST)
+
+    return param
 }
 
-func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
-    call := &ast.CallExpression{Token: p.curToken, Function: function}
-    call.Arguments = p.parseCallArguments()
+func (p *Parser) parseClassStatement() *ast.ClassStatement {
+    statement := &ast.ClassStatement{Token: p.curToken}
 
-    if p.peekToken.Type == token.NEWL {
+    if !p.expectPeek(token.NAME) {
+        return nil
+    }
+
+    statement.Name = p.parseName().(*ast.Name)
+    p.bind(statement.Name)
+
+    if p.peekTokenIs(token.LPAR) {
         p.nextToken()
+
+        if !p.peekTokenIs(token.RPAR) {
+            p.nextToken()
+            statement.Base = p.parseExpression(LOWEST)
+        }
+
+        if !p.expectPeek(token.RPAR) {
+            return nil
+        }
     }
 
-    return call
+    if !p.expectPeek(token.COLON) {
+        return nil
+    }
+
+    loopDepth := p.loopDepth
+    p.loopDepth = 0
+    p.openScope(nil)
+    p.scope.class = true
+    statement.Body = p.parseSuite()
+    statement.Scope = p.closeScope()
+    p.loopDepth = loopDepth
+
+    return statement
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-    args := []ast.Expression{}
+func (p *Parser) parseLambdaExpression() ast.Expression {
+    lambda := &ast.LambdaExpression{Token: p.curToken}
 
-    if p.peekTokenIs(token.RPAR) {
-        p.nextToken()
-        return args
+    lambda.Arguments = p.parseFunctionArguments(token.COLON)
+    if lambda.Arguments == nil {
+        return nil
     }
 
     p.nextToken()
 
-    arg := p.parseExpression(LOWEST)
-    args = append(args, arg)
+    p.openScope(lambda.Arguments)
+    lambda.Body = p.parseExpression(LOWEST)
+    lambda.Scope = p.closeScope()
 
-    for p.peekTokenIs(token.COMMA) {
-        p.nextToken()
+    return lambda
+}
+
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+    call := &ast.CallExpression{Token: p.curToken, Function: function}
+    call.Arguments = p.parseCallArguments()
+
+    return call
+}
+
+// parseCallArguments parses the arguments of a call: positional ones,
+// *iterable, name=value and **mapping, in the order Python allows them.
+func (p *Parser) parseCallArguments() []ast.Expression {
+    args := []ast.Expression{}
+    keywords := map[string]bool{}
+
+    var hasKeyword, hasMapping bool
+
+    for !p.peekTokenIs(token.RPAR) {
         p.nextToken()
 
-        arg := p.parseExpression(LOWEST)
+        arg := p.parseCallArgument()
+
+        switch arg := arg.(type) {
+        case *ast.KeywordArgument:
+            if keywords[arg.Name.Value] {
+                p.errorAt(
+                    arg.Pos(),
+                    "SyntaxError: keyword argument repeated: %s",
+                    arg.Name.Value,
+                )
+            }
+
+            keywords[arg.Name.Value] = true
+            hasKeyword = true
+        case *ast.PrefixExpression:
+            if arg.Operator == "**" {
+                hasMapping = true
+            } else if arg.Operator == "*" && hasMapping {
+                p.errorAt(
+                    arg.Pos(),
+                    "SyntaxError: iterable argument unpacking follows keyword argument unpacking",
+                )
+            } else if arg.Operator != "*" {
+                p.checkPositionalArgument(arg, hasKeyword, hasMapping)
+            }
+        default:
+            if arg != nil {
+                p.checkPositionalArgument(arg, hasKeyword, hasMapping)
+            }
+        }
+
         args = append(args, arg)
+
+        if !p.peekTokenIs(token.COMMA) {
+            break
+        }
+
+        p.nextToken()
     }
 
     if !p.expectPeek(token.RPAR) {
@@ -515,30 +1624,114 @@ func (p *Parser) parseCallArguments() []ast.Expression {
     return args
 }
 
+func (p *Parser) parseCallArgument() ast.Expression {
+    if !p.tokenIs(token.NAME) || !p.peekTokenIs(token.ASSIGN) {
+        return p.parseExpression(LOWEST)
+    }
+
+    arg := &ast.KeywordArgument{
+        Token: p.curToken,
+        Name: &ast.Name{Token: p.curToken, Value: p.curToken.Literal},
+    }
+
+    p.nextToken()
+    p.nextToken()
+
+    arg.Value = p.parseExpression(LOWEST)
+
+    return arg
+}
+
+func (p *Parser) checkPositionalArgument(
+    arg ast.Expression, hasKeyword, hasMapping bool) {
+
+    switch {
+    case hasMapping:
+        p.errorAt(
+            arg.Pos(),
+            "SyntaxError: positional argument follows keyword argument unpacking",
+        )
+    case hasKeyword:
+        p.errorAt(
+            arg.Pos(),
+            "SyntaxError: positional argument follows keyword argument",
+        )
+    }
+}
+
 func (p *Parser) parseListExpression() ast.Expression {
+    tok := p.curToken
+
     p.nextToken()
 
     if p.tokenIs(token.RBR) {
-        return &ast.ListLiteral{Arr: []ast.Expression{}}
+        return &ast.ListLiteral{Token: tok, Arr: []ast.Expression{}}
     }
 
-    if p.peekTokenIs(token.COMMA) {
-        arr := []ast.Expression{p.parseExpression(LOWEST)}
+    arr := []ast.Expression{p.parseExpression(LOWEST)}
 
-        for p.peekTokenIs(token.COMMA){
-            p.nextToken()
-            p.nextToken()
-            arr = append(arr, p.parseExpression(LOWEST))
+    for p.peekTokenIs(token.COMMA){
+        p.nextToken()
+
+        // A trailing comma is allowed before the closing bracket.
+        if p.peekTokenIs(token.RBR) {
+            break
+        }
+
+        p.nextToken()
+        arr = append(arr, p.parseExpression(LOWEST))
+    }
+
+    if !p.expectPeek(token.RBR) {
+        return nil
+    }
+
+    return &ast.ListLiteral{Token: tok, Arr: arr}
+}
+
+func (p *Parser) parseDictExpression() ast.Expression {
+    dict := &ast.DictLiteral{Token: p.curToken}
+    dict.Keys = []ast.Expression{}
+    dict.Values = []ast.Expression{}
+
+    for !p.peekTokenIs(token.RSQB) {
+        p.nextToken()
+
+        key := p.parseExpression(LOWEST)
+
+        if !p.expectPeek(token.COLON) {
+            return nil
         }
 
-        if !p.expectPeek(token.RBR) {
+        p.nextToken()
+
+        value := p.parseExpression(LOWEST)
+
+        dict.Keys = append(dict.Keys, key)
+        dict.Values = append(dict.Values, value)
+
+        if !p.peekTokenIs(token.RSQB) && !p.expectPeek(token.COMMA) {
             return nil
         }
+    }
 
-        return &ast.ListLiteral{Arr: arr}
+    if !p.expectPeek(token.RSQB) {
+        return nil
     }
 
-    return nil
+    return dict
+}
+
+func (p *Parser) parseAttributeExpression(object ast.Expression) ast.Expression {
+    expression := &ast.AttributeExpression{Token: p.curToken, Object: object}
+
+    if !p.expectPeek(token.NAME) {
+        return nil
+    }
+
+    expression.Name = p.parseName().(*ast.Name)
+
+    return expression
 }
 
 func (p *Parser) parseIndexExpression(sequence ast.Expression) ast.Expression {
@@ -548,7 +1741,7 @@ func (p *Parser) parseIndexExpression(sequence ast.Expression) ast.Expression {
     }
     p.nextToken()
 
-    expression.Value = p.parseExpression(LOWEST)
+    expression.Value = p.parseSubscript()
 
     if !p.expectPeek(token.RBR) {
         return nil
@@ -557,6 +1750,43 @@ func (p *Parser) parseIndexExpression(sequence ast.Expression) ast.Expression {
     return expression
 }
 
+// parseSubscript parses the index of an index expression, either a single
+// expression or a slice.
+func (p *Parser) parseSubscript() ast.Expression {
+    slice := &ast.SliceExpression{Token: p.curToken}
+
+    if !p.tokenIs(token.COLON) {
+        index := p.parseExpression(LOWEST)
+        if !p.peekTokenIs(token.COLON) {
+            return index
+        }
+
+        slice.Start = index
+        p.nextToken()
+    }
+
+    slice.Stop = p.parseSliceBound()
+
+    if p.peekTokenIs(token.COLON) {
+        p.nextToken()
+        slice.Step = p.parseSliceBound()
+    }
+
+    return slice
+}
+
+// parseSliceBound parses the optional expression following a colon of a
+// slice.
+func (p *Parser) parseSliceBound() ast.Expression {
+    if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBR) {
+        return nil
+    }
+
+    p.nextToken()
+
+    return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) tokenIs (t token.TokenType) bool {
     return p.curToken.Type == t
 }
@@ -5,6 +5,7 @@ import (
 
 	"mxshs/pyinterpreter/ast"
 	"mxshs/pyinterpreter/lexer"
+	"mxshs/pyinterpreter/token"
 )
 
 func TestIntegerLiterals(t *testing.T) {
@@ -94,6 +95,9 @@ func TestStringLiterals(t *testing.T) {
     } {
         {"\"420.69\"", "420.69"},
         {"\"hello world\"", "hello world"},
+        {"'single'", "single"},
+        {"'a\\'b\\tc'", "a'b\tc"},
+        {"r'a\\n'", "a\\n"},
     }
 
     for _, tt := range tests {
@@ -192,10 +196,10 @@ func testBoolExpression(program *ast.Program, value bool, t *testing.T) {
 }
 
 func TestAssignmentStatements(t *testing.T) {
-    input := `a 3
-    b =
-    cd 3535
-    `
+    input := `a = 3
+b = 5
+cd = 3535
+`
 
     l := lexer.GetLexer(input)
     p := GetParser(l)
@@ -573,6 +577,8 @@ func TestArrayExpression(t *testing.T) {
     } {
         {"[]", []string{}},
         {"[1, \"2\", 3.0]", []string{"1", "2", "3.0"}},
+        {"[1]", []string{"1"}},
+        {"[a + b, c,]", []string{"(a + b)", "c"}},
     }
 
     for _, tt := range tests {
@@ -730,3 +736,1104 @@ func testAssignmentStatement(t *testing.T, s ast.Statement, name string) bool {
     return true
 }
 
+
+func TestErrorPositions(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"f(1", "test.py:1:4: SyntaxError: expected ')', found end of line"},
+        {"a = 1\n(a", "test.py:2:3: SyntaxError: expected ')', found end of line"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetFileLexer("test.py", tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) == 0 {
+            t.Fatalf("expected parser error for %q, got none", tt.input)
+        }
+
+        if errors[0] != tt.expected {
+            t.Fatalf("expected parser error: %q, got: %q", tt.expected, errors[0])
+        }
+    }
+}
+
+func TestNestedBlocks(t *testing.T) {
+    input := `def f(a, b):
+    if a > b:
+        def g(c):
+            return c
+        return g(a)
+    else:
+        return b
+f(1, 2)
+`
+
+    l := lexer.GetLexer(input)
+    p := GetParser(l)
+    program := p.ParseProgram()
+
+    if len(p.Errors()) != 0 {
+        t.Fatalf("unexpected parser errors: %v", p.Errors())
+    }
+
+    if len(program.Statements) != 2 {
+        t.Fatalf(
+            "expected number of statements: %d, got: %d",
+            2,
+            len(program.Statements),
+        )
+    }
+
+    function, ok := program.Statements[0].(*ast.FunctionStatement)
+    if !ok {
+        t.Fatalf(
+            "expected statement of type ast.FunctionStatement, got: %T",
+            program.Statements[0],
+        )
+    }
+
+    expected := "(if (a > b) def(c)return creturn (g(a)) else return b)"
+    if function.Body.String() != expected {
+        t.Fatalf(
+            "expected function body to be %s, got: %s",
+            expected,
+            function.Body.String(),
+        )
+    }
+}
+
+func TestUnexpectedIndent(t *testing.T) {
+    l := lexer.GetLexer("a = 1\n    b = 2\n")
+    p := GetParser(l)
+    p.ParseProgram()
+
+    errors := p.Errors()
+    if len(errors) != 1 || errors[0] != "2:5: IndentationError: unexpected indent" {
+        t.Fatalf("expected unexpected indent error, got: %v", errors)
+    }
+}
+
+func TestWhileStatements(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"while a < 3: a", "(while (a < 3) a)"},
+        {
+            "while a:\n\tif b:\n\t\tbreak\n\tcontinue\nelse:\n\treturn 1",
+            "(while a (if b break)continue else return 1)",
+        },
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if len(program.Statements) != 1 {
+            t.Fatalf(
+                "expected number of statements: %d, got: %d",
+                1,
+                len(program.Statements),
+            )
+        }
+
+        statement, ok := program.Statements[0].(*ast.WhileStatement)
+        if !ok {
+            t.Fatalf(
+                "expected statement of type ast.WhileStatement, got: %T",
+                program.Statements[0],
+            )
+        }
+
+        if statement.String() != tt.expected {
+            t.Fatalf(
+                "expected while statement to be %s, got: %s",
+                tt.expected,
+                statement.String(),
+            )
+        }
+    }
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"break", "1:1: SyntaxError: 'break' outside loop"},
+        {"continue", "1:1: SyntaxError: 'continue' not properly in loop"},
+        {
+            "while a:\n\tdef f():\n\t\tbreak",
+            "3:3: SyntaxError: 'break' outside loop",
+        },
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) != 1 || errors[0] != tt.expected {
+            t.Fatalf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestForStatements(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"for x in xs: x", "(for x in xs x)"},
+        {
+            "for i in range(3):\n\tif i: break\nelse:\n\treturn 1",
+            "(for i in (range(3)) (if i break) else return 1)",
+        },
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        statement, ok := program.Statements[0].(*ast.ForStatement)
+        if !ok {
+            t.Fatalf(
+                "expected statement of type ast.ForStatement, got: %T",
+                program.Statements[0],
+            )
+        }
+
+        if statement.String() != tt.expected {
+            t.Fatalf(
+                "expected for statement to be %s, got: %s",
+                tt.expected,
+                statement.String(),
+            )
+        }
+    }
+}
+
+func TestDictExpression(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"{}", "dict({})"},
+        {"{\"a\": 1, b: 2 + 3}", "dict({a: 1, b: (2 + 3), })"},
+        {"{1: 2,}", "dict({1: 2, })"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+        if !ok {
+            t.Fatalf(
+                "expected statement of type ast.ExpressionStatement, got: %T",
+                program.Statements[0],
+            )
+        }
+
+        dict, ok := statement.Expression.(*ast.DictLiteral)
+        if !ok {
+            t.Fatalf(
+                "expected expression of type ast.DictLiteral, got: %T",
+                statement.Expression,
+            )
+        }
+
+        if dict.String() != tt.expected {
+            t.Fatalf(
+                "expected dict literal to be %s, got: %s",
+                tt.expected,
+                dict.String(),
+            )
+        }
+    }
+}
+
+func TestIndexAssignment(t *testing.T) {
+    l := lexer.GetLexer("a[\"b\"] = 3")
+    p := GetParser(l)
+    program := p.ParseProgram()
+
+    if len(p.Errors()) != 0 {
+        t.Fatalf("unexpected parser errors: %v", p.Errors())
+    }
+
+    statement, ok := program.Statements[0].(*ast.AssignStatement)
+    if !ok {
+        t.Fatalf(
+            "expected statement of type ast.AssignStatement, got: %T",
+            program.Statements[0],
+        )
+    }
+
+    if _, ok := statement.Target.(*ast.IndexExpression); !ok {
+        t.Fatalf(
+            "expected assignment target of type ast.IndexExpression, got: %T",
+            statement.Target,
+        )
+    }
+
+    if statement.String() != "(a[b]) = 3" {
+        t.Fatalf("expected assignment to be (a[b]) = 3, got: %s", statement.String())
+    }
+}
+
+func TestTryStatements(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"try: a\nexcept: pass", "(try a except pass)"},
+        {
+            "try:\n\ta\nexcept KeyError as e:\n\tb\nexcept:\n\traise\nelse:\n\tc\nfinally:\n\td",
+            "(try a except KeyError as e b except raise else c finally d)",
+        },
+        {"try: a\nfinally: raise ValueError(1)", "(try a finally raise (ValueError(1)))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        statement, ok := program.Statements[0].(*ast.TryStatement)
+        if !ok {
+            t.Fatalf(
+                "expected statement of type ast.TryStatement, got: %T",
+                program.Statements[0],
+            )
+        }
+
+        if statement.String() != tt.expected {
+            t.Fatalf(
+                "expected try statement to be %s, got: %s",
+                tt.expected,
+                statement.String(),
+            )
+        }
+    }
+}
+
+func TestTryWithoutHandlers(t *testing.T) {
+    l := lexer.GetLexer("try: a\nb")
+    p := GetParser(l)
+    p.ParseProgram()
+
+    errors := p.Errors()
+    expected := "2:1: SyntaxError: expected 'except' or 'finally' block"
+    if len(errors) != 1 || errors[0] != expected {
+        t.Fatalf("expected parser error: %q, got: %v", expected, errors)
+    }
+}
+
+func TestFunctionParameters(t *testing.T) {
+    tests := []struct{
+        input string
+        expected []string
+    } {
+        {
+            "def f(a, b=1, *args, c, d=0, **kw): pass",
+            []string{"a", "b=1", "*args", "c", "d=0", "**kw"},
+        },
+        {"def f(a, *, b): pass", []string{"a", "*", "b"}},
+        {"def f(a,): pass", []string{"a"}},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        statement := program.Statements[0].(*ast.FunctionStatement)
+
+        if len(statement.Arguments) != len(tt.expected) {
+            t.Fatalf(
+                "expected %d parameters, got: %d",
+                len(tt.expected),
+                len(statement.Arguments),
+            )
+        }
+
+        for idx, val := range tt.expected {
+            if statement.Arguments[idx].String() != val {
+                t.Errorf(
+                    "expected %s at position %d, got: %s",
+                    val,
+                    idx,
+                    statement.Arguments[idx].String(),
+                )
+            }
+        }
+    }
+}
+
+func TestCallKeywordArguments(t *testing.T) {
+    input := "f(1, *xs, a=2, **kw)"
+
+    l := lexer.GetLexer(input)
+    p := GetParser(l)
+    program := p.ParseProgram()
+
+    if len(p.Errors()) != 0 {
+        t.Fatalf("unexpected parser errors: %v", p.Errors())
+    }
+
+    statement := program.Statements[0].(*ast.ExpressionStatement)
+    call := statement.Expression.(*ast.CallExpression)
+
+    expected := []string{"1", "(*xs)", "a=2", "(**kw)"}
+
+    for idx, val := range expected {
+        if call.Arguments[idx].String() != val {
+            t.Errorf(
+                "expected %s at position %d, got: %s",
+                val,
+                idx,
+                call.Arguments[idx].String(),
+            )
+        }
+    }
+
+    if _, ok := call.Arguments[2].(*ast.KeywordArgument); !ok {
+        t.Errorf("expected *ast.KeywordArgument, got: %T", call.Arguments[2])
+    }
+}
+
+func TestArgumentErrors(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {
+            "def f(a=1, b): pass",
+            "1:12: SyntaxError: non-default argument follows default argument",
+        },
+        {
+            "def f(a, a): pass",
+            "1:10: SyntaxError: duplicate argument 'a' in function definition",
+        },
+        {"def f(*): pass", "1:7: SyntaxError: named arguments must follow bare *"},
+        {
+            "def f(**kw, a): pass",
+            "1:13: SyntaxError: arguments cannot follow var-keyword argument",
+        },
+        {"f(a=1, 2)", "1:8: SyntaxError: positional argument follows keyword argument"},
+        {"f(a=1, a=2)", "1:8: SyntaxError: keyword argument repeated: a"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) != 1 || errors[0] != tt.expected {
+            t.Errorf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestLambdaExpression(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"lambda: 1", "(lambda : 1)"},
+        {"lambda a, b=1: a + b", "(lambda a, b=1: (a + b))"},
+        {"f(key=lambda x: -x)", "(f(key=(lambda x: (-x))))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestFunctionScopes(t *testing.T) {
+    input := "def f(a):\n\tglobal g\n\tb = 1\n\tg = 2\n" +
+        "\tdef h():\n\t\tnonlocal b\n\t\tb = 3\n" +
+        "\tfor i in a: pass"
+
+    l := lexer.GetLexer(input)
+    p := GetParser(l)
+    program := p.ParseProgram()
+
+    if len(p.Errors()) != 0 {
+        t.Fatalf("unexpected parser errors: %v", p.Errors())
+    }
+
+    outer := program.Statements[0].(*ast.FunctionStatement)
+
+    for _, name := range []string{"a", "b", "h", "i"} {
+        if !outer.Scope.Locals[name] {
+            t.Errorf("expected %s to be local to f, got: %v", name, outer.Scope.Locals)
+        }
+    }
+
+    if outer.Scope.Locals["g"] || !outer.Scope.Globals["g"] {
+        t.Errorf("expected g to be global in f, got: %+v", outer.Scope)
+    }
+
+    inner := outer.Body.Statements[3].(*ast.FunctionStatement)
+
+    if inner.Scope.Locals["b"] || !inner.Scope.Nonlocals["b"] {
+        t.Errorf("expected b to be nonlocal in h, got: %+v", inner.Scope)
+    }
+}
+
+func TestScopeErrors(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {
+            "nonlocal x",
+            "1:1: SyntaxError: nonlocal declaration not allowed at module level",
+        },
+        {
+            "def f():\n\tnonlocal x\n\tx = 1",
+            "2:11: SyntaxError: no binding for nonlocal 'x' found",
+        },
+        {
+            "def f(a):\n\tglobal a",
+            "2:9: SyntaxError: name 'a' is parameter and global",
+        },
+        {
+            "def f():\n\ta = 1\n\tglobal a",
+            "3:9: SyntaxError: name 'a' is assigned to before global declaration",
+        },
+        {
+            "def f():\n\tglobal a\n\tdef g():\n\t\tnonlocal a",
+            "4:12: SyntaxError: no binding for nonlocal 'a' found",
+        },
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) != 1 || errors[0] != tt.expected {
+            t.Errorf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestClassStatement(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"class A: pass", "class A: pass"},
+        {"class A():\n\tx = 1", "class A: x = 1"},
+        {"class B(mod.A):\n\tdef f(self): pass", "class B(mod.A): def(self)pass"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if _, ok := program.Statements[0].(*ast.ClassStatement); !ok {
+            t.Fatalf("expected *ast.ClassStatement, got: %T", program.Statements[0])
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestAttributeExpression(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"a.b", "a.b"},
+        {"a.b.c(1).d", "(a.b.c(1)).d"},
+        {"a.b[0] + 1", "((a.b[0]) + 1)"},
+        {"self.x = 1", "self.x = 1"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestTupleExpression(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"(1, 2)", "tuple((1, 2, ))"},
+        {"(1,)", "tuple((1, ))"},
+        {"()", "tuple(())"},
+        {"(1)", "1"},
+        {"1, 2 + 3", "tuple((1, (2 + 3), ))"},
+        {"a, b = b, a", "tuple((a, b, )) = tuple((b, a, ))"},
+        {"a, *b = c", "tuple((a, (*b), )) = c"},
+        {"x, = c", "tuple((x, )) = c"},
+        {"(a, [b, c]) = d", "tuple((a, list([b, c, ]), )) = d"},
+        {"def f():\n\treturn 1, 2", "def()return tuple((1, 2, ))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestAssignmentTargetErrors(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"a, *b, *c = d", "1:8: SyntaxError: multiple starred expressions in assignment"},
+        {"*a = b", "1:1: SyntaxError: starred assignment target must be in a list or tuple"},
+        {"a, 1 = b", "1:4: SyntaxError: cannot assign to literal"},
+        {"for a, f() in b:\n\tpass", "1:8: SyntaxError: cannot assign to function call"},
+        {"a < b = 1", "1:1: SyntaxError: cannot assign to comparison"},
+        {"-a = 1", "1:1: SyntaxError: cannot assign to expression"},
+        {"for a + b in c: pass", "1:5: SyntaxError: cannot assign to expression"},
+        {"for x +, y in z: pass", "1:8: SyntaxError: invalid syntax"},
+        {"a, (b +) = c", "1:8: SyntaxError: invalid syntax"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) != 1 || errors[0] != tt.expected {
+            t.Errorf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestAugmentedAssignment(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"x += 1", "x += 1"},
+        {"x -= 1 + 2", "x -= (1 + 2)"},
+        {"xs[0] *= 2", "(xs[0]) *= 2"},
+        {"a.b /= 2", "a.b /= 2"},
+        {"t += 1, 2", "t += tuple((1, 2, ))"},
+        {"x **= 2", "x **= 2"},
+        {"x //= 2", "x //= 2"},
+        {"xs[0] <<= 1", "(xs[0]) <<= 1"},
+        {"a.b ^= c", "a.b ^= c"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if _, ok := program.Statements[0].(*ast.AugAssignStatement); !ok {
+            t.Fatalf(
+                "expected statement of type ast.AugAssignStatement, got: %T",
+                program.Statements[0],
+            )
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+
+    errorTests := []struct {
+        input string
+        expected string
+    } {
+        {"a, b += 1", "1:1: SyntaxError: 'tuple' is an illegal expression for augmented assignment"},
+        {"f() += 1", "1:1: SyntaxError: 'function call' is an illegal expression for augmented assignment"},
+        {"1 -= a", "1:1: SyntaxError: 'literal' is an illegal expression for augmented assignment"},
+        {"a + b *= 2", "1:1: SyntaxError: 'expression' is an illegal expression for augmented assignment"},
+    }
+
+    for _, tt := range errorTests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        if errors := p.Errors(); len(errors) != 1 || errors[0] != tt.expected {
+            t.Errorf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestSliceExpression(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"a[1:2]", "(a[1:2:])"},
+        {"a[1:-1:2]", "(a[1:(-1):2])"},
+        {"a[:]", "(a[::])"},
+        {"a[::-1]", "(a[::(-1)])"},
+        {"a[b + 1:]", "(a[(b + 1)::])"},
+        {"a[:2] = b", "(a[:2:]) = b"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestBooleanOperators(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"a and b", "(a and b)"},
+        {"a or b and c", "(a or (b and c))"},
+        {"a and b or c", "((a and b) or c)"},
+        {"not a", "(not a)"},
+        {"not a == b", "(not (a == b))"},
+        {"not a and b", "((not a) and b)"},
+        {"a < b or not c", "((a < b) or (not c))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestComparisonExpression(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"a <= b", "(a <= b)"},
+        {"a >= b + 1", "(a >= (b + 1))"},
+        {"0 <= i < n", "(0 <= i < n)"},
+        {"a < b == c != d", "(a < b == c != d)"},
+        {"a in b", "(a in b)"},
+        {"a not in b", "(a not in b)"},
+        {"a is None", "(a is None)"},
+        {"a is not None", "(a is not None)"},
+        {"not a in b", "(not (a in b))"},
+        {"a == b and b < c", "((a == b) and (b < c))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestArithmeticPrecedence(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"a // b % c", "((a // b) % c)"},
+        {"a + b << c", "((a + b) << c)"},
+        {"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+        {"a & b == c", "((a & b) == c)"},
+        {"~a + b", "((~a) + b)"},
+        {"-a ** b", "(-(a ** b))"},
+        {"a ** b ** c", "(a ** (b ** c))"},
+        {"a ** -b", "(a ** (-b))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestStringConcatenation(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {`"a" 'b' """c"""`, "abc"},
+        {`b"a" b'b'`, `b"ab"`},
+        {`f"x={x + 1}!"`, `f"x={(x + 1)}!"`},
+        {`f"{a!r:>{w}} {{}}"`, `f"{a!r:>{w}} {{}}"`},
+        {`"a" f"{b}" "c"`, `f"a{b}c"`},
+        {`f"{x=}"`, `f"x={x!r}"`},
+        {`f"{x = :5}"`, `f"x = {x:5}"`},
+        {`f"{a, b}"`, `f"{tuple((a, b, ))}"`},
+        {`f"{d['k']}" rf"\d{x}"`, `f"{(d[k])}\d{x}"`},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %s, got: %s", tt.expected, program.String())
+        }
+    }
+}
+
+func TestFormattedStringErrors(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {`f"{}"`, "1:4: SyntaxError: f-string: valid expression required before '}'"},
+        {`f"a}"`, "1:4: SyntaxError: f-string: single '}' is not allowed"},
+        {`f"{x!z}"`, "1:5: SyntaxError: f-string: invalid conversion character: expected 's', 'r', or 'a'"},
+        {`f"{x"`, "1:5: SyntaxError: f-string: expecting '}'"},
+        {`f"{x:{y:{z}}}"`, "1:9: SyntaxError: f-string: expressions nested too deeply"},
+        {`"a" b"b"`, "1:5: SyntaxError: cannot mix bytes and nonbytes literals"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) != 1 || errors[0] != tt.expected {
+            t.Errorf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestNumericLiterals(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    } {
+        {"0xff", int64(255)},
+        {"0o17", int64(15)},
+        {"0B101", int64(5)},
+        {"1_000_000", int64(1000000)},
+        {"0x_7fff_ffff_ffff_ffff", int64(9223372036854775807)},
+        {"1e-9", 1e-9},
+        {"2.5E3", 2500.0},
+        {".5", 0.5},
+        {"1_0.2_5", 10.25},
+        {"3j", "3j"},
+        {"1.5e1J", "1.5e1J"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+        testParserErrors(t, p)
+
+        expr := program.Statements[0].(*ast.ExpressionStatement).Expression
+
+        switch expected := tt.expected.(type) {
+        case int64:
+            literal, ok := expr.(*ast.IntegerLiteral)
+            if !ok || literal.Value != expected {
+                t.Errorf("expected %q to be the int %d, got: %#v", tt.input, expected, expr)
+            }
+        case float64:
+            literal, ok := expr.(*ast.FloatLiteral)
+            if !ok || literal.Value != expected {
+                t.Errorf("expected %q to be the float %g, got: %#v", tt.input, expected, expr)
+            }
+        case string:
+            literal, ok := expr.(*ast.ImaginaryLiteral)
+            if !ok || literal.String() != expected {
+                t.Errorf("expected %q to be an imaginary literal, got: %#v", tt.input, expr)
+            }
+        }
+    }
+}
+
+func TestIllegalTokenErrors(t *testing.T) {
+    tests := []struct{
+        input string
+        expected string
+    } {
+        {"x = 1 $ 2", "1:7: SyntaxError: invalid syntax"},
+        {"print(0b2)", "1:7: SyntaxError: invalid binary literal"},
+        {"x = (1 +\n€)", "2:1: SyntaxError: invalid character '€' (U+20AC)"},
+        {"print(\"abc", "1:7: SyntaxError: unterminated string literal (detected at line 1)"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) != 1 || errors[0] != tt.expected {
+            t.Errorf("expected parser error: %q, got: %v", tt.expected, errors)
+        }
+    }
+}
+
+func TestErrorRecovery(t *testing.T) {
+    input := `a 3
+x = 1
+if x
+    y = 2
+else:
+    y = 3
+z = (1 + )
+print(x)
+    w = 3
+`
+
+    l := lexer.GetFileLexer("test.py", input)
+    p := GetParser(l)
+    program := p.ParseProgram()
+
+    expected := []string{
+        "test.py:1:3: SyntaxError: invalid syntax",
+        "test.py:3:5: SyntaxError: expected ':', found end of line",
+        "test.py:7:10: SyntaxError: invalid syntax",
+        "test.py:9:5: IndentationError: unexpected indent",
+    }
+
+    errors := p.Errors()
+    if len(errors) != len(expected) {
+        t.Fatalf("expected %d parser errors, got: %q", len(expected), errors)
+    }
+
+    for i, msg := range expected {
+        if errors[i] != msg {
+            t.Errorf("errors[%d] - expected: %q, got: %q", i, msg, errors[i])
+        }
+    }
+
+    // Only the statements without errors are kept.
+    statements := []string{"x = 1", "(print(x))", "w = 3"}
+
+    if len(program.Statements) != len(statements) {
+        t.Fatalf("expected %d statements, got: %d", len(statements), len(program.Statements))
+    }
+
+    for i, s := range statements {
+        if program.Statements[i] == nil || program.Statements[i].String() != s {
+            t.Errorf("statements[%d] - expected: %q, got: %v", i, s, program.Statements[i])
+        }
+    }
+}
+
+func TestMalformedExpressions(t *testing.T) {
+    inputs := []string{
+        "for -: pass",
+        "for x +: pass",
+        "for not in x: pass",
+        "x + ) = 1",
+        "a.-b = 1",
+        "a.+b += 1",
+        "a.(b) += 1",
+        "f(1.x, k=1)",
+        "x = [1, 2 +]",
+        "y = a[1 +] * 2",
+    }
+
+    for _, input := range inputs {
+        l := lexer.GetLexer(input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) == 0 {
+            t.Errorf("expected parser errors for %q, got none", input)
+        }
+
+        // Statements with errors are dropped, none is left with nil parts.
+        for _, statement := range program.Statements {
+            if statement != nil {
+                _ = statement.String()
+            }
+        }
+    }
+}
+
+func TestSyntaxErrorTokens(t *testing.T) {
+    l := lexer.GetLexer("def f(a:\n    pass")
+    p := GetParser(l)
+    p.ParseProgram()
+
+    errors := p.SyntaxErrors()
+    if len(errors) != 1 {
+        t.Fatalf("expected 1 parser error, got: %v", p.Errors())
+    }
+
+    err := errors[0]
+    if err.Class != "SyntaxError" || err.Expected != token.RPAR {
+        t.Fatalf("expected a SyntaxError expecting ')', got: %+v", err)
+    }
+
+    if err.Found == nil || err.Found.Type != token.COLON || err.Pos != err.Found.Pos {
+        t.Fatalf("expected the error to be at the found ':', got: %+v", err)
+    }
+}
+
+func TestElifClauses(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {
+            "if a:\n\treturn 1\nelif b:\n\treturn 2\nelif c: return 3\nelse:\n\treturn 4",
+            "(if a return 1 elif b return 2 elif c return 3 else return 4)",
+        },
+        {"if a: x = 1\nelif b: x = 2", "(if a x = 1 elif b x = 2)"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors: %v", p.Errors())
+        }
+
+        if len(program.Statements) != 1 {
+            t.Fatalf("expected 1 statement, got: %d", len(program.Statements))
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %q, got: %q", tt.expected, program.String())
+        }
+    }
+}
+
+func TestConditionalExpression(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    } {
+        {"a if b else c", "(a if b else c)"},
+        {"a if b else c if d else e", "(a if b else (c if d else e))"},
+        {"a or b if c or d else e and f", "((a or b) if (c or d) else (e and f))"},
+        {"x = a + 1 if not b else 2", "x = ((a + 1) if (not b) else 2)"},
+        {"f(a if b else c, d)", "(f((a if b else c), d))"},
+        {"lambda: a if b else c", "(lambda : (a if b else c))"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.GetLexer(tt.input)
+        p := GetParser(l)
+        program := p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Fatalf("unexpected parser errors for %q: %v", tt.input, p.Errors())
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected program: %q, got: %q", tt.expected, program.String())
+        }
+    }
+}
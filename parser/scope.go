@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"mxshs/pyinterpreter/ast"
+	"mxshs/pyinterpreter/token"
+)
+
+// funcScope collects the bindings of the function that is being parsed.
+type funcScope struct {
+    parent *funcScope
+    scope *ast.Scope
+    params map[string]bool
+    // class is set for class bodies, their names are not visible to the
+    // functions nested in them.
+    class bool
+    // pending are nonlocal declarations of the function and of the
+    // functions nested in it that still need a binding in an enclosing
+    // function.
+    pending []*ast.Name
+}
+
+// openScope starts the scope of a function body binding params.
+func (p *Parser) openScope(params []*ast.Parameter) {
+    p.scope = &funcScope{
+        parent: p.scope,
+        scope: ast.NewScope(),
+        params: map[string]bool{},
+    }
+
+    for _, param := range params {
+        if param.Name != nil {
+            p.scope.params[param.Name.Value] = true
+            p.bind(param.Name)
+        }
+    }
+}
+
+// closeScope ends the innermost function scope and returns it. Nonlocal
+// names it can't resolve are left to the enclosing functions, at module
+// level they are an error.
+func (p *Parser) closeScope() *ast.Scope {
+    closed := p.scope
+    p.scope = closed.parent
+
+    for _, name := range closed.pending {
+        if !closed.class && closed.scope.Locals[name.Value] {
+            continue
+        }
+
+        if p.scope == nil {
+            p.errorAt(
+                name.Pos(),
+                "SyntaxError: no binding for nonlocal '%s' found",
+                name.Value,
+            )
+            continue
+        }
+
+        p.scope.pending = append(p.scope.pending, name)
+    }
+
+    return closed.scope
+}
+
+// bind records that name is assigned in the current scope, which makes it
+// local to the function unless it's declared global or nonlocal.
+func (p *Parser) bind(name *ast.Name) {
+    if p.scope == nil {
+        return
+    }
+
+    scope := p.scope.scope
+    if scope.Globals[name.Value] || scope.Nonlocals[name.Value] {
+        return
+    }
+
+    scope.Locals[name.Value] = true
+}
+
+func (p *Parser) parseGlobalStatement() *ast.GlobalStatement {
+    statement := &ast.GlobalStatement{
+        Token: p.curToken,
+        Nonlocal: p.tokenIs(token.NONLOCAL),
+    }
+
+    if statement.Nonlocal && p.scope == nil {
+        p.errorAt(
+            p.curToken.Pos,
+            "SyntaxError: nonlocal declaration not allowed at module level",
+        )
+    }
+
+    for {
+        if !p.expectPeek(token.NAME) {
+            return nil
+        }
+
+        name := p.parseName().(*ast.Name)
+        statement.Names = append(statement.Names, name)
+
+        p.declare(name, statement.Nonlocal)
+
+        if !p.peekTokenIs(token.COMMA) {
+            break
+        }
+
+        p.nextToken()
+    }
+
+    p.endStatement()
+
+    return statement
+}
+
+// declare marks name as global or nonlocal in the current function.
+func (p *Parser) declare(name *ast.Name, nonlocal bool) {
+    if p.scope == nil {
+        return
+    }
+
+    kind, other := "global", "nonlocal"
+    if nonlocal {
+        kind, other = other, kind
+    }
+
+    scope := p.scope.scope
+
+    switch {
+    case p.scope.params[name.Value]:
+        p.errorAt(
+            name.Pos(),
+            "SyntaxError: name '%s' is parameter and %s",
+            name.Value,
+            kind,
+        )
+    case scope.Locals[name.Value]:
+        p.errorAt(
+            name.Pos(),
+            "SyntaxError: name '%s' is assigned to before %s declaration",
+            name.Value,
+            kind,
+        )
+    case nonlocal && scope.Globals[name.Value],
+        !nonlocal && scope.Nonlocals[name.Value]:
+        p.errorAt(
+            name.Pos(),
+            "SyntaxError: name '%s' is %s and %s",
+            name.Value,
+            other,
+            kind,
+        )
+    case nonlocal:
+        if !scope.Nonlocals[name.Value] {
+            p.scope.pending = append(p.scope.pending, name)
+        }
+
+        scope.Nonlocals[name.Value] = true
+    default:
+        scope.Globals[name.Value] = true
+    }
+}
@@ -30,22 +30,30 @@ func StartREPL(in io.Reader, out io.Writer) {
 
     for {
 
-        res := read(scanner, out)
+        res, ok := read(scanner, out)
+        if !ok {
+            return
+        }
 
-        l := lexer.GetLexer(res)
+        l := lexer.GetFileLexer("<stdin>", res)
 
         p := parser.GetParser(l)
         program := p.ParseProgram()
 
-        if len(p.Errors()) != 0 {
-            for _, err := range p.Errors() {
-                io.WriteString(out, "\t" + err + "\n")
+        if errors := p.SyntaxErrors(); len(errors) != 0 {
+            for _, err := range errors {
+                io.WriteString(out, err.FormatExcerpt(res) + "\n")
             }
             continue
         }
 
         evaluated := eval.Eval(program, env)
 
+        if err, ok := evaluated.(*object.Error); ok {
+            io.WriteString(out, err.FormatTraceback() + "\n")
+            continue
+        }
+
         if evaluated != nil {
             io.WriteString(out, evaluated.Inspect())
             io.WriteString(out, "\n")
@@ -53,7 +61,9 @@ func StartREPL(in io.Reader, out io.Writer) {
     }
 }
 
-func read(scanner *bufio.Scanner, out io.Writer) string {
+// read reads a block of input lines, it returns false once the input is
+// exhausted.
+func read(scanner *bufio.Scanner, out io.Writer) (string, bool) {
     var block []string
     var indent int
     
@@ -61,13 +71,15 @@ func read(scanner *bufio.Scanner, out io.Writer) string {
 
     for {
         io.WriteString(out, ">>" + indentstr)
-        scanner.Scan()
+        if !scanner.Scan() {
+            return strings.Join(block, "\n"), len(block) != 0
+        }
 
         line := scanner.Text()
 
         if len(line) == 0 {
             if indent == 0 {
-                return strings.Join(block, "\n")
+                return strings.Join(block, "\n"), true
             } else {
                 indent -= 4
                 indentstr = strings.Repeat(" ", indent + 1)
@@ -1,29 +1,72 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
     Type TokenType
     Literal string
+    Pos Position
+}
+
+// Position is a location in the source. Line and Column start at 1, Offset
+// is a byte offset starting at 0. The zero value is not a valid position.
+type Position struct {
+    Filename string
+    Offset int
+    Line int
+    Column int
+}
+
+func (p Position) IsValid() bool {
+    return p.Line > 0
+}
+
+func (p Position) String() string {
+    if !p.IsValid() {
+        return p.Filename
+    }
+
+    if p.Filename != "" {
+        return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+    }
+
+    return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 
 const (
+    ILLEGAL = "ILLEGAL"
     EOF = "EOF"
     NEWL = "\n"
+    INDENT = "INDENT"
+    DEDENT = "DEDENT"
 
     NAME = "NAME"
     INT = "INT"
     FLOAT = "FLOAT"
+    IMAG = "IMAG"
     STRING = "STRING"
+    BYTES = "BYTES"
+    FSTRING = "FSTRING"
 
     ASSIGN = "="
     PLUS = "+"
     MINUS = "-"
     STAR = "*"
     SLASH = "/"
+    DOUBLE_SLASH = "//"
+    PERCENT = "%"
     BANG = "!"
 
+    AMPERSAND = "&"
+    PIPE = "|"
+    CARET = "^"
+    TILDE = "~"
+    LSHIFT = "<<"
+    RSHIFT = ">>"
+
     LT = "<"
     GT = ">"
 
@@ -36,10 +79,19 @@ const (
     MINUS_ASSIGN = "-="
     TIMES_ASSIGN = "*="
     DIV_ASSIGN = "/="
+    FLOOR_DIV_ASSIGN = "//="
+    MOD_ASSIGN = "%="
+    POW_ASSIGN = "**="
+    AND_ASSIGN = "&="
+    OR_ASSIGN = "|="
+    XOR_ASSIGN = "^="
+    LSHIFT_ASSIGN = "<<="
+    RSHIFT_ASSIGN = ">>="
  
     DOUBLE_STAR = "**"
 
     COMMA = ","
+    DOT = "."
     // SEMICOLON = ";"
     COLON = ":"
 
@@ -51,22 +103,64 @@ const (
     RSQB = "}"
 
     FDEF = "def"
+    CLASS = "CLASS"
     BTRUE = "TRUE"
     BFALSE = "FALSE"
     IF = "IF"
     ELSE = "ELSE"
+    ELIF = "ELIF"
     FOR = "FOR"
+    IN = "IN"
+    WHILE = "WHILE"
+    BREAK = "BREAK"
+    CONTINUE = "CONTINUE"
     RETURN = "RETURN"
+    PASS = "PASS"
+    TRY = "TRY"
+    EXCEPT = "EXCEPT"
+    FINALLY = "FINALLY"
+    RAISE = "RAISE"
+    AS = "AS"
+    LAMBDA = "LAMBDA"
+    GLOBAL = "GLOBAL"
+    NONLOCAL = "NONLOCAL"
+    AND = "AND"
+    OR = "OR"
+    NOT = "NOT"
+    IS = "IS"
+    NONE = "NONE"
 )
 
 var keywords = map[string]TokenType{
     "def": FDEF,
+    "class": CLASS,
     "true": BTRUE, 
     "false": BFALSE, 
+    "True": BTRUE,
+    "False": BFALSE,
+    "None": NONE,
     "if": IF,
     "else": ELSE,
+    "elif": ELIF,
     "for": FOR,
+    "in": IN,
+    "while": WHILE,
+    "break": BREAK,
+    "continue": CONTINUE,
     "return": RETURN, 
+    "pass": PASS,
+    "try": TRY,
+    "except": EXCEPT,
+    "finally": FINALLY,
+    "raise": RAISE,
+    "as": AS,
+    "lambda": LAMBDA,
+    "global": GLOBAL,
+    "nonlocal": NONLOCAL,
+    "and": AND,
+    "or": OR,
+    "not": NOT,
+    "is": IS,
 }
 
 func LookupKey(key string) TokenType{